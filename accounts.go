@@ -0,0 +1,209 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Account struct for account
+// A group or user a package's commands create with groupadd or useradd. Kind
+// is group or user. ID is the GID of a group and the UID of a user, zero when
+// the command lets the system pick one. Group and Groups are a user's primary
+// and supplementary groups.
+type Account struct {
+	Kind    string   `json:"kind" yaml:"kind"`
+	Name    string   `json:"name" yaml:"name"`
+	ID      int      `json:"id,omitempty" yaml:"id,omitempty"`
+	Group   string   `json:"group,omitempty" yaml:"group,omitempty"`
+	Groups  []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Home    string   `json:"home,omitempty" yaml:"home,omitempty"`
+	Shell   string   `json:"shell,omitempty" yaml:"shell,omitempty"`
+	Comment string   `json:"comment,omitempty" yaml:"comment,omitempty"`
+	System  bool     `json:"system,omitempty" yaml:"system,omitempty"`
+	Command int      `json:"command" yaml:"command"`
+	Package string   `json:"package,omitempty" yaml:"package,omitempty"`
+}
+
+// accountOptions maps the long options of groupadd and useradd to their short form
+var accountOptions = map[string]string{
+	"--comment":        "-c",
+	"--home-dir":       "-d",
+	"--home":           "-d",
+	"--gid":            "-g",
+	"--groups":         "-G",
+	"--shell":          "-s",
+	"--uid":            "-u",
+	"--system":         "-r",
+	"--create-home":    "-m",
+	"--no-create-home": "-M",
+	"--force":          "-f",
+	"--non-unique":     "-o",
+}
+
+// accountSwitches are the options of groupadd and useradd without a value
+var accountSwitches = map[string]bool{"-r": true, "-m": true, "-M": true, "-f": true, "-o": true, "-N": true, "-U": true, "-l": true}
+
+// stepAccount func takes step Step input and returns Account, bool
+func stepAccount(step Step) (Account, bool) {
+	account := Account{}
+	switch step.Name() {
+	case "groupadd":
+		account.Kind = "group"
+	case "useradd":
+		account.Kind = "user"
+	default:
+		return account, false
+	}
+	args := step.Argv[1:]
+	for i := 0; i < len(args); i++ {
+		arg, value := splitOption(args[i])
+		if short, ok := accountOptions[arg]; ok {
+			arg = short
+		}
+		if !strings.HasPrefix(arg, "-") {
+			account.Name = arg
+			continue
+		}
+		if accountSwitches[arg] {
+			account.System = account.System || arg == "-r"
+			continue
+		}
+		if value == "" && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		switch {
+		case arg == "-c":
+			account.Comment = value
+		case arg == "-d":
+			account.Home = value
+		case arg == "-s":
+			account.Shell = value
+		case arg == "-u" && account.Kind == "user", arg == "-g" && account.Kind == "group":
+			account.ID, _ = strconv.Atoi(value)
+		case arg == "-g":
+			account.Group = value
+		case arg == "-G":
+			account.Groups = strings.Split(value, ",")
+		}
+	}
+	return account, account.Name != ""
+}
+
+// ExtractAccounts func takes cmds []Command input and returns []Account
+// Accounts are taken from the groupadd and useradd steps of the commands.
+func ExtractAccounts(cmds []Command) []Account {
+	accounts := make([]Account, 0)
+	for _, cmd := range cmds {
+		steps, _, err := ParseSteps(cmd, ".")
+		if err != nil {
+			continue
+		}
+		for _, step := range steps {
+			if account, ok := stepAccount(step); ok {
+				account.Command = cmd.Index
+				accounts = append(accounts, account)
+			}
+		}
+	}
+	return accounts
+}
+
+// MergeAccounts func takes pkgs []*PackageInformation input and returns []Account, []error
+// Groups come before users so they exist when the users are created. An
+// account created by several packages is kept once, and names or IDs used
+// for different accounts are reported.
+func MergeAccounts(pkgs []*PackageInformation) ([]Account, []error) {
+	var (
+		errs   []error
+		groups []Account
+		users  []Account
+		byName = make(map[string]Account)
+		byID   = make(map[string]Account)
+	)
+	for _, pkgInfo := range pkgs {
+		for _, account := range pkgInfo.Accounts {
+			account.Package = pkgInfo.Name
+			name := account.Kind + " " + account.Name
+			if other, ok := byName[name]; ok {
+				if other.ID != account.ID {
+					errs = append(errs, fmt.Errorf("%s is %d for %s but %d for %s",
+						name, other.ID, other.Package, account.ID, account.Package))
+				}
+				continue
+			}
+			byName[name] = account
+			if account.ID != 0 {
+				id := fmt.Sprintf("%s %d", account.Kind, account.ID)
+				if other, ok := byID[id]; ok {
+					errs = append(errs, fmt.Errorf("%s is %s for %s and %s for %s",
+						id, other.Name, other.Package, account.Name, account.Package))
+				}
+				byID[id] = account
+			}
+			if account.Kind == "group" {
+				groups = append(groups, account)
+			} else {
+				users = append(users, account)
+			}
+		}
+	}
+	accounts := make([]Account, 0, len(groups)+len(users))
+	return append(append(accounts, groups...), users...), errs
+}
+
+// runAccounts func takes args []string input and returns error
+func runAccounts(args []string) error {
+	var (
+		overrides   string
+		asjson      bool
+		recommended bool
+	)
+	flags := flag.NewFlagSet("accounts", flag.ExitOnError)
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&asjson, "json", false, "Output JSON")
+	flags.BoolVar(&recommended, "recommended", false, "Include recommended dependencies")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s accounts [options] <book|package> [target...]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return fmt.Errorf("accounts requires a book or package")
+	}
+	pkgs, err := LoadPackages(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	pkgs = NewDependencyGraph(pkgs, recommended).OrderedPackages(flags.Args()[1:])
+	accounts, errs := MergeAccounts(pkgs)
+	for _, err := range errs {
+		warn(err)
+	}
+	if asjson {
+		jsn, err := json.MarshalIndent(accounts, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to convert to json : %v", err)
+		}
+		fmt.Printf("%s\n", jsn)
+		return nil
+	}
+	yml, err := yaml.Marshal(accounts)
+	if err != nil {
+		return fmt.Errorf("failed to convert to yaml : %v", err)
+	}
+	fmt.Printf("%s", yml)
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestExtractAccounts func takes no input and returns t *testing.T
+func TestExtractAccounts(t *testing.T) {
+	cmds := []Command{
+		{Cmd: "make install", Index: 0, Root: true},
+		{Cmd: `groupadd -g 18 messagebus &&
+useradd -c "D-Bus Message Daemon User" -d /run/dbus \
+        -u 18 -g messagebus -s /bin/false messagebus`, Index: 1, Root: true},
+		{Cmd: "groupadd --system plugdev &&\nuseradd -r -m --groups=plugdev,audio --shell /bin/bash colord", Index: 2, Root: true},
+	}
+	assert.DeepEqual(t, ExtractAccounts(cmds), []Account{
+		{Kind: "group", Name: "messagebus", ID: 18, Command: 1},
+		{Kind: "user", Name: "messagebus", ID: 18, Group: "messagebus", Home: "/run/dbus", Shell: "/bin/false",
+			Comment: "D-Bus Message Daemon User", Command: 1},
+		{Kind: "group", Name: "plugdev", System: true, Command: 2},
+		{Kind: "user", Name: "colord", Groups: []string{"plugdev", "audio"}, Shell: "/bin/bash", System: true, Command: 2},
+	})
+}
+
+// TestMergeAccounts func takes no input and returns t *testing.T
+func TestMergeAccounts(t *testing.T) {
+	dbus := &PackageInformation{Name: "dbus", Accounts: []Account{
+		{Kind: "group", Name: "messagebus", ID: 18},
+		{Kind: "user", Name: "messagebus", ID: 18, Group: "messagebus"},
+	}}
+	avahi := &PackageInformation{Name: "avahi", Accounts: []Account{
+		{Kind: "group", Name: "avahi", ID: 84},
+		{Kind: "user", Name: "avahi", ID: 18, Group: "avahi"},
+		{Kind: "group", Name: "messagebus", ID: 18},
+		{Kind: "group", Name: "netdev", ID: 86},
+	}}
+	accounts, errs := MergeAccounts([]*PackageInformation{dbus, avahi})
+	assert.Equal(t, len(errs), 1)
+	assert.ErrorContains(t, errs[0], "user 18 is messagebus for dbus and avahi for avahi")
+	names := make([]string, 0)
+	for _, account := range accounts {
+		names = append(names, account.Kind+" "+account.Name+" "+account.Package)
+	}
+	assert.DeepEqual(t, names, []string{
+		"group messagebus dbus",
+		"group avahi avahi",
+		"group netdev avahi",
+		"user messagebus dbus",
+		"user avahi avahi",
+	})
+}
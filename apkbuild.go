@@ -0,0 +1,165 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"crypto/sha512"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// apkbuildTemplate is the Alpine Linux APKBUILD layout
+const apkbuildTemplate = `# Generated by cmdext from {{ .Name }}-{{ .Version }}
+pkgname={{ .PkgName }}
+pkgver={{ .PkgVer }}
+pkgrel=0
+pkgdesc="{{ .Summary }}"
+url="{{ .URL }}"
+arch="all"
+license="unknown"
+depends="{{ join .Depends " " }}"
+makedepends="{{ join .MakeDepends " " }}"
+{{ if not .HasCheck }}options="!check"
+{{ end }}source="{{ range $i, $s := .Sources }}{{ if $i }}
+	{{ end }}{{ $s.Archive }}{{ end }}
+	"
+
+# the directory the source unpacks to
+_srcdir() {
+	{{ .SrcDirCmd }}
+}
+{{ range $stage := .Stages }}
+{{ $stage.Func }}() {
+	builddir="$srcdir/$(_srcdir)"
+	cd "$builddir"
+{{ range $stage.Commands }}
+{{ . }}
+{{ end }}}
+{{ end }}
+sha512sums="
+{{ range .Checksums }}{{ . }}
+{{ end }}"
+`
+
+// apkbuildData struct for apkbuilddata
+type apkbuildData struct {
+	*PackageInformation
+	PkgName     string
+	PkgVer      string
+	Summary     string
+	URL         string
+	SrcDirCmd   string
+	Depends     []string
+	MakeDepends []string
+	Sources     []Source
+	Checksums   []string
+	HasCheck    bool
+	Stages      []pkgbuildStage
+}
+
+// apkbuildPlaceholder stands in for checksums of sources missing from the cache
+const apkbuildPlaceholder = "FIXME"
+
+// patchCommand matches a patch applied from the parent directory
+var patchCommand = regexp.MustCompile(`^patch\s.*\.\./(\S+)$`)
+
+// doubleQuote func takes s string input and returns string
+// The result is safe inside a double quoted shell string.
+func doubleQuote(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s)
+}
+
+// cachedSHA512 func takes cache, archive string input and returns string
+// The checksum of the archive in the cache directory or a placeholder.
+func cachedSHA512(cache, archive string) string {
+	if sum := cachedChecksum(cache, archive, sha512.New()); sum != "" {
+		return sum
+	}
+	return apkbuildPlaceholder
+}
+
+// appliedPatch func takes cmd Command, sources []Source input and returns bool
+// abuild's default_prepare applies patches listed in source itself.
+func appliedPatch(cmd Command, sources []Source) bool {
+	lines := commandLines(cmd.Cmd)
+	if len(lines) != 1 {
+		return false
+	}
+	m := patchCommand.FindStringSubmatch(lines[0])
+	if m == nil {
+		return false
+	}
+	for _, src := range sources {
+		if isPatch(src.Archive) && sourceFile(src.Archive) == m[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// EmitAPKBUILD func takes pkgInfo *PackageInformation, opts EmitOptions input and returns []byte, error
+// Checksums are computed for sources found in opts.SourceCache, the others are
+// left as placeholders for abuild checksum to fill in.
+func EmitAPKBUILD(pkgInfo *PackageInformation, opts EmitOptions) ([]byte, error) {
+	primary := primarySource(pkgInfo)
+	archive := ""
+	if file := unpackedArchive(pkgInfo); file != "" {
+		archive = `"$srcdir/` + doubleQuote(file) + `"`
+	}
+	requires := dependencyNames(pkgInfo.Dependencies.Requires)
+	data := apkbuildData{
+		PackageInformation: pkgInfo,
+		PkgName:            strings.ToLower(pkgInfo.Name),
+		PkgVer:             strings.ReplaceAll(pkgInfo.Version, "-", "_"),
+		Summary:            doubleQuote(summary(pkgInfo.Description)),
+		URL:                homepage(primary.Archive),
+		SrcDirCmd:          sourceDirCommand(pkgInfo, archive),
+		Depends:            requires,
+		MakeDepends:        requires,
+		Sources:            emitterSources(pkgInfo),
+	}
+	for _, src := range data.Sources {
+		data.Checksums = append(data.Checksums, cachedSHA512(opts.SourceCache, src.Archive)+"  "+sourceFile(src.Archive))
+	}
+	stages := stagedStages(pkgInfo.Commands, opts.StagingDir(`"$pkgdir"`))
+	dirs := commandDirs(pkgInfo.Commands)
+	data.HasCheck = len(stages[StageCheck]) > 0
+	for _, stage := range Stages {
+		cmds := stages[stage]
+		if len(cmds) == 0 && stage != StagePrepare && stage != StageInstall {
+			continue
+		}
+		s := pkgbuildStage{Func: pkgbuildFuncs[stage]}
+		if stage == StagePrepare {
+			s.Commands = append(s.Commands, "default_prepare")
+		}
+		run := make([]Command, 0, len(cmds))
+		for _, cmd := range cmds {
+			if stage != StagePrepare || !appliedPatch(cmd, data.Sources) {
+				run = append(run, cmd)
+			}
+		}
+		texts, _ := stageTexts(run, dirs, "$builddir", ".")
+		s.Commands = append(s.Commands, texts...)
+		if len(s.Commands) == 0 {
+			s.Commands = []string{":"}
+		}
+		data.Stages = append(data.Stages, s)
+	}
+	tmpl, err := ParseTemplate("APKBUILD", apkbuildTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return Render(tmpl, data)
+}
+
+func init() {
+	RegisterEmitter(Emitter{
+		Name: "apkbuild",
+		Emit: singleFile(func(pkgInfo *PackageInformation) string {
+			return path.Join(pkgInfo.Name, "APKBUILD")
+		}, EmitAPKBUILD),
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestEmitAPKBUILD func takes no input and returns t *testing.T
+func TestEmitAPKBUILD(t *testing.T) {
+	pkgInfo := tclPackage(withPatch)
+	content, err := EmitAPKBUILD(pkgInfo, EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	apkbuild := string(content)
+	fmt.Printf("%s\n", apkbuild)
+	assert.Assert(t, strings.Contains(apkbuild, "pkgname=tcl\npkgver=8.6.9\npkgrel=0\n"))
+	assert.Assert(t, strings.Contains(apkbuild, `pkgdesc="The Tcl package contains the Tool Command Language, a robust general-purpose scripting language"`))
+	assert.Assert(t, strings.Contains(apkbuild, "depends=\"zlib\"\nmakedepends=\"zlib\"\n"))
+	assert.Assert(t, strings.Contains(apkbuild, "_srcdir() {\n\ttar -tf \"$srcdir/tcl8.6.9-src.tar.gz\" | head -n 1"))
+	assert.Assert(t, strings.Contains(apkbuild, "prepare() {\n\tbuilddir=\"$srcdir/$(_srcdir)\"\n\tcd \"$builddir\"\n\ndefault_prepare\n\ntar -xf"))
+	assert.Assert(t, strings.Contains(apkbuild, "check() {\n\tbuilddir=\"$srcdir/$(_srcdir)\"\n\tcd \"$builddir\"\n\ncd \"$builddir/unix\"\n\nmake test\n}"))
+	assert.Assert(t, !strings.Contains(apkbuild, "patch -Np1"))
+	assert.Assert(t, strings.Contains(apkbuild, "make DESTDIR=\"$pkgdir\" install &&"))
+	assert.Assert(t, strings.Contains(apkbuild, "sha512sums=\"\nFIXME  tcl8.6.9-src.tar.gz\nFIXME  tcl8.6.9-html.tar.gz\n"))
+
+	cache, err := ioutil.TempDir("", "apkbuild")
+	assert.Assert(t, is.Nil(err))
+	err = ioutil.WriteFile(path.Join(cache, "tcl8.6.9-src.tar.gz"), []byte("tcl"), 0644)
+	assert.Assert(t, is.Nil(err))
+	sum := sha512.Sum512([]byte("tcl"))
+	content, err = EmitAPKBUILD(pkgInfo, EmitOptions{SourceCache: cache})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.Contains(string(content), hex.EncodeToString(sum[:])+"  tcl8.6.9-src.tar.gz\nFIXME  tcl8.6.9-html.tar.gz\n"))
+
+	pkgInfo.Commands = pkgInfo.Commands[:2]
+	content, err = EmitAPKBUILD(pkgInfo, EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.Contains(string(content), "options=\"!check\"\n"))
+	assert.Assert(t, strings.Contains(string(content), "package() {\n\tbuilddir=\"$srcdir/$(_srcdir)\"\n\tcd \"$builddir\"\n\n:\n}"))
+}
+
+// TestDoubleQuote func takes no input and returns t *testing.T
+func TestDoubleQuote(t *testing.T) {
+	assert.Equal(t, doubleQuote(`say "hi" to $USER`), `say \"hi\" to \$USER`)
+}
@@ -0,0 +1,192 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadPackageInformation func takes file, overrides string input and returns *PackageInformation, error
+// HTML pages are extracted, YAML and JSON files are read as previously
+// generated output. The package's override in the overrides directory, if
+// any, is applied.
+func ReadPackageInformation(file, overrides string) (*PackageInformation, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pkgInfo := &PackageInformation{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".html", ".htm":
+		pkgInfo, err = CreatePackageInformationFromPage(b, file)
+	case ".json":
+		err = json.Unmarshal(b, pkgInfo)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, pkgInfo)
+	default:
+		return nil, fmt.Errorf("unsupported file type %s", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s : %v", file, err)
+	}
+	if err := ApplyOverrides(pkgInfo, overrides); err != nil {
+		return nil, err
+	}
+	return pkgInfo, nil
+}
+
+// isPackagePage func takes file string input and returns bool
+// Book pages without a package information block are skipped.
+func isPackagePage(file string) bool {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(b), `class="package"`)
+}
+
+// LoadPackages func takes root, overrides string input and returns []*PackageInformation, error
+// root may be a book tree of HTML pages, a directory of generated YAML/JSON, a
+// catalog or a single file. Packages are returned sorted by name, except for
+// catalogs which keep book order, with the overrides directory applied.
+func LoadPackages(root, overrides string) ([]*PackageInformation, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		switch strings.ToLower(filepath.Ext(root)) {
+		case ".json", ".yaml", ".yml":
+			if catalog, err := ReadCatalog(root); err == nil && len(catalog.Chapters) > 0 {
+				if err := catalog.ApplyOverrides(overrides); err != nil {
+					return nil, err
+				}
+				return catalog.Packages(), nil
+			}
+		}
+		pkgInfo, err := ReadPackageInformation(root, overrides)
+		if err != nil {
+			return nil, err
+		}
+		return []*PackageInformation{pkgInfo}, nil
+	}
+	pkgs := make([]*PackageInformation, 0)
+	files := make(map[string]string)
+	err = filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".html", ".htm":
+			if !isPackagePage(file) {
+				return nil
+			}
+		case ".json", ".yaml", ".yml":
+		default:
+			return nil
+		}
+		pkgInfo, err := ReadPackageInformation(file, overrides)
+		if err != nil {
+			return err
+		}
+		if pkgInfo.Name == "" {
+			return nil
+		}
+		if other, ok := files[pkgInfo.Name]; ok {
+			warn(fmt.Errorf("package %s is loaded from %s and %s, keeping both", pkgInfo.Name, other, file))
+		} else {
+			files[pkgInfo.Name] = file
+		}
+		pkgs = append(pkgs, pkgInfo)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return pkgs[i].Name < pkgs[j].Name
+	})
+	return pkgs, nil
+}
+
+// PackageKeys func takes pkgs []*PackageInformation input and returns []string
+// A package is keyed by its name. Books hold some names twice, Python-2 and
+// Python-3 both extract as "python", so those are keyed by name and major
+// version ("python-3"), or by name and full version when the major version
+// is shared as well.
+func PackageKeys(pkgs []*PackageInformation) []string {
+	count := make(map[string]int, len(pkgs))
+	majors := make(map[string]int, len(pkgs))
+	for _, pkg := range pkgs {
+		count[pkg.Name]++
+		majors[majorKey(pkg)]++
+	}
+	keys := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		switch {
+		case count[pkg.Name] == 1:
+			keys[i] = pkg.Name
+		case majors[majorKey(pkg)] == 1:
+			keys[i] = majorKey(pkg)
+		default:
+			keys[i] = pkg.Name + "-" + strings.ToLower(pkg.Version)
+		}
+	}
+	return keys
+}
+
+// majorKey func takes pkg *PackageInformation input and returns string
+func majorKey(pkg *PackageInformation) string {
+	version := MustParseVersion(pkg.Version)
+	if len(version.Parts) == 0 {
+		return pkg.Name + "-" + strings.ToLower(pkg.Version)
+	}
+	return fmt.Sprintf("%s-%d", pkg.Name, version.Parts[0])
+}
+
+// PackageMap func takes pkgs []*PackageInformation input and returns map[string]*PackageInformation
+// Packages are keyed by PackageKeys.
+func PackageMap(pkgs []*PackageInformation) map[string]*PackageInformation {
+	m := make(map[string]*PackageInformation, len(pkgs))
+	for i, key := range PackageKeys(pkgs) {
+		m[key] = pkgs[i]
+	}
+	return m
+}
+
+// DependencyName func takes dep string input and returns string
+// Dependencies are extracted from link text such as "cmake-3.15.2"; the
+// trailing version is dropped so the name matches PackageInformation.Name.
+func DependencyName(dep string) string {
+	dep = strings.ToLower(strings.TrimSpace(dep))
+	i := strings.LastIndex(dep, "-")
+	if i < 0 || i == len(dep)-1 {
+		return dep
+	}
+	if !unicode.IsDigit(rune(dep[i+1])) {
+		return dep
+	}
+	return dep[:i]
+}
+
+// dependencyNames func takes deps []string input and returns []string
+func dependencyNames(deps []string) []string {
+	names := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		names = append(names, DependencyName(dep))
+	}
+	return names
+}
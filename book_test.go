@@ -0,0 +1,41 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"sort"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestLoadPackagesDuplicate func takes no input and returns t *testing.T
+func TestLoadPackagesDuplicate(t *testing.T) {
+	dir := writePackages(t,
+		&PackageInformation{Name: "python", Version: "2.7.16"},
+		&PackageInformation{Name: "python", Version: "3.7.4"},
+		&PackageInformation{Name: "tcl", Version: "8.6.9"},
+		&PackageInformation{Name: "tcl", Version: "8.6.10"},
+		&PackageInformation{Name: "tk", Version: "8.6.9", Dependencies: Dependencies{Requires: []string{"Python-2.7.16", "tcl-8.6.10"}}},
+	)
+	defer os.RemoveAll(dir)
+	pkgs, err := LoadPackages(dir, "")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(pkgs), 5)
+	keys := PackageKeys(pkgs)
+	sort.Strings(keys)
+	assert.DeepEqual(t, keys, []string{"python-2", "python-3", "tcl-8.6.10", "tcl-8.6.9", "tk"})
+	g := NewDependencyGraph(pkgs, false)
+	assert.DeepEqual(t, g.Edges("tk"), []string{"python-2", "tcl-8.6.10"})
+	assert.Equal(t, g.Key("python"), "python-3")
+	assert.Equal(t, g.Key("Python-3.7.4"), "python-3")
+
+	unique := writePackages(t, &PackageInformation{Name: "tcl", Version: "8.6.9"}, &PackageInformation{Name: "tk", Version: "8.6.9"})
+	defer os.RemoveAll(unique)
+	pkgs, err = LoadPackages(unique, "")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(pkgs), 2)
+}
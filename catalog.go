@@ -0,0 +1,223 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog struct for catalog
+type Catalog struct {
+	Title    string           `json:"title" yaml:"title"`
+	Chapters []CatalogChapter `json:"chapters" yaml:"chapters"`
+}
+
+// CatalogChapter struct for catalogchapter
+type CatalogChapter struct {
+	Part     string         `json:"part,omitempty" yaml:"part,omitempty"`
+	Title    string         `json:"title" yaml:"title"`
+	Page     string         `json:"page" yaml:"page"`
+	Packages []CatalogEntry `json:"packages" yaml:"packages"`
+}
+
+// CatalogEntry struct for catalogentry
+type CatalogEntry struct {
+	Page                string `json:"page" yaml:"page"`
+	*PackageInformation `yaml:",inline"`
+}
+
+// tocEntry struct for tocentry
+type tocEntry struct {
+	part    string
+	title   string
+	page    string
+	entries []string
+}
+
+// readTOC func takes doc *goquery.Document input and returns []tocEntry
+func readTOC(doc *goquery.Document) []tocEntry {
+	toc := make([]tocEntry, 0)
+	doc.Find(".toc li.chapter").Each(func(i int, s *goquery.Selection) {
+		chapter := tocEntry{}
+		if part := s.ParentsFiltered("li.part").First(); part.Length() > 0 {
+			chapter.part = cleanTitle(part.ChildrenFiltered("h3").First().Text())
+		}
+		heading := s.ChildrenFiltered("h4").First()
+		chapter.title = cleanTitle(heading.Text())
+		chapter.page, _ = heading.Find("a").First().Attr("href")
+		s.Find("li.sect1").Each(func(i int, s *goquery.Selection) {
+			link := s.ChildrenFiltered("a").First()
+			if link.Length() == 0 {
+				link = s.Find("a").First()
+			}
+			href, ok := link.Attr("href")
+			if !ok {
+				return
+			}
+			chapter.entries = append(chapter.entries, strings.Split(href, "#")[0])
+		})
+		toc = append(toc, chapter)
+	})
+	return toc
+}
+
+// CreateCatalog func takes index, overrides string input and returns *Catalog, error
+// index is the book's index.html or the directory holding it. Pages are
+// resolved relative to the index and only package pages are catalogued, with
+// the overrides directory applied.
+func CreateCatalog(index, overrides string) (*Catalog, error) {
+	if info, err := os.Stat(index); err == nil && info.IsDir() {
+		index = filepath.Join(index, "index.html")
+	}
+	b, err := ioutil.ReadFile(index)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ReadDoc(b)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Dir(index)
+	catalog := &Catalog{
+		Title:    strings.Join(strings.Fields(doc.Find("title").First().Text()), " "),
+		Chapters: []CatalogChapter{},
+	}
+	toc := readTOC(doc)
+	if len(toc) == 0 {
+		return nil, fmt.Errorf("no chapters found in %s", index)
+	}
+	for _, entry := range toc {
+		chapter := CatalogChapter{
+			Part:     entry.part,
+			Title:    entry.title,
+			Page:     entry.page,
+			Packages: []CatalogEntry{},
+		}
+		for _, page := range entry.entries {
+			file := filepath.Join(root, filepath.FromSlash(page))
+			if !isPackagePage(file) {
+				continue
+			}
+			pkgInfo, err := ReadPackageInformation(file, overrides)
+			if err != nil {
+				return nil, err
+			}
+			chapter.Packages = append(chapter.Packages, CatalogEntry{Page: page, PackageInformation: pkgInfo})
+		}
+		catalog.Chapters = append(catalog.Chapters, chapter)
+	}
+	return catalog, nil
+}
+
+// ReadCatalog func takes file string input and returns *Catalog, error
+func ReadCatalog(file string) (*Catalog, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	catalog := &Catalog{}
+	if strings.HasSuffix(file, ".json") {
+		err = json.Unmarshal(b, catalog)
+	} else {
+		err = yaml.Unmarshal(b, catalog)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog %s : %v", file, err)
+	}
+	return catalog, nil
+}
+
+// ApplyOverrides func takes dir string input and returns error
+// The overrides in dir are applied to every package of the catalog.
+func (c *Catalog) ApplyOverrides(dir string) error {
+	for _, pkgInfo := range c.Packages() {
+		if err := ApplyOverrides(pkgInfo, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Packages func takes no input and returns []*PackageInformation
+// Packages are returned in book order.
+func (c *Catalog) Packages() []*PackageInformation {
+	pkgs := make([]*PackageInformation, 0)
+	for _, chapter := range c.Chapters {
+		for _, entry := range chapter.Packages {
+			if entry.PackageInformation != nil {
+				pkgs = append(pkgs, entry.PackageInformation)
+			}
+		}
+	}
+	return pkgs
+}
+
+// ToYAML func takes no input and returns []byte, error
+func (c *Catalog) ToYAML() ([]byte, error) {
+	content, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to yaml : %v", err)
+	}
+	return content, nil
+}
+
+// ToPrettyJSON func takes no input and returns []byte, error
+func (c *Catalog) ToPrettyJSON() ([]byte, error) {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to json : %v", err)
+	}
+	return content, nil
+}
+
+// runCatalog func takes args []string input and returns error
+func runCatalog(args []string) error {
+	var (
+		output    string
+		overrides string
+		asjson    bool
+	)
+	flags := flag.NewFlagSet("catalog", flag.ExitOnError)
+	flags.StringVar(&output, "output", "", "Path to write the catalog to (default stdout)")
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&asjson, "json", false, "Output JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s catalog [options] <index.html|book>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("catalog requires the book index")
+	}
+	catalog, err := CreateCatalog(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	var content []byte
+	if asjson {
+		content, err = catalog.ToPrettyJSON()
+	} else {
+		content, err = catalog.ToYAML()
+	}
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Printf("%s\n", content)
+		return nil
+	}
+	return ioutil.WriteFile(output, content, 0644) //nolint:gosec
+}
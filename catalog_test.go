@@ -0,0 +1,122 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// writeBook func takes t *testing.T, pages map[string]string input and returns string
+func writeBook(t *testing.T, pages map[string]string) string {
+	dir, err := ioutil.TempDir("", "book")
+	assert.Assert(t, is.Nil(err))
+	for page, content := range pages {
+		file := filepath.Join(dir, filepath.FromSlash(page))
+		err = os.MkdirAll(filepath.Dir(file), 0755)
+		assert.Assert(t, is.Nil(err))
+		err = ioutil.WriteFile(file, []byte(content), 0644)
+		assert.Assert(t, is.Nil(err))
+	}
+	return dir
+}
+
+// testPage func takes name, version, requires string input and returns string
+func testPage(name, version, requires string) string {
+	return fmt.Sprintf(`<html>
+  <head><title>%[1]s-%[2]s</title></head>
+  <body>
+    <div class="sect1">
+      <h1 class="sect1"><a id="%[1]s" name="%[1]s"></a>%[1]s-%[2]s</h1>
+      <div class="package">
+        <p>The <span class="application">%[1]s</span> package.</p>
+        <div class="itemizedlist">
+          <ul class="compact">
+            <li><p>Download (HTTP): <a class="ulink" href="https://example.com/%[1]s-%[2]s.tar.xz">https://example.com/%[1]s-%[2]s.tar.xz</a></p></li>
+            <li><p>Download MD5 sum: 0123456789abcdef0123456789abcdef</p></li>
+          </ul>
+        </div>
+        <p class="required">%[3]s</p>
+      </div>
+      <div class="installation">
+        <pre class="userinput"><kbd class="command">./configure --prefix=/usr &amp;&amp;
+make</kbd></pre>
+        <pre class="root"><kbd class="command">make install</kbd></pre>
+      </div>
+    </div>
+  </body>
+</html>
+`, name, version, requires)
+}
+
+// testIndex is a book index with one part, two chapters and a non-package page
+const testIndex = `<html>
+  <head><title>Beyond Linux From Scratch - Version 9.0</title></head>
+  <body>
+    <div class="toc">
+      <ul>
+        <li class="part"><h3>II. <a href="postlfs/postlfs.html">Post LFS Configuration</a></h3>
+          <ul>
+            <li class="chapter"><h4>1. <a href="introduction/introduction.html">Welcome</a></h4>
+              <ul>
+                <li class="sect1"><a href="introduction/welcome.html">Welcome to BLFS</a></li>
+              </ul>
+            </li>
+            <li class="chapter"><h4>10. <a href="general/genlib.html">General Libraries</a></h4>
+              <ul>
+                <li class="sect1"><a href="general/zlib.html">zlib-1.2.11</a></li>
+                <li class="sect1"><a href="general/tcl.html#tcl">tcl-8.6.9</a></li>
+              </ul>
+            </li>
+          </ul>
+        </li>
+      </ul>
+    </div>
+  </body>
+</html>
+`
+
+// TestCreateCatalog func takes no input and returns t *testing.T
+func TestCreateCatalog(t *testing.T) {
+	dir := writeBook(t, map[string]string{
+		"index.html":                testIndex,
+		"introduction/welcome.html": `<html><head><title>Welcome to BLFS</title></head><body></body></html>`,
+		"general/zlib.html":         testPage("zlib", "1.2.11", ""),
+		"general/tcl.html":          testPage("tcl", "8.6.9", `<a href="zlib.html">zlib-1.2.11</a>`),
+	})
+	defer os.RemoveAll(dir)
+
+	catalog, err := CreateCatalog(dir, "")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, catalog.Title, "Beyond Linux From Scratch - Version 9.0")
+	assert.Equal(t, len(catalog.Chapters), 2)
+	assert.Equal(t, catalog.Chapters[0].Part, "Post LFS Configuration")
+	assert.Equal(t, catalog.Chapters[0].Title, "Welcome")
+	assert.Equal(t, len(catalog.Chapters[0].Packages), 0)
+	general := catalog.Chapters[1]
+	assert.Equal(t, general.Title, "General Libraries")
+	assert.Equal(t, general.Page, "general/genlib.html")
+	assert.Equal(t, len(general.Packages), 2)
+	assert.Equal(t, general.Packages[1].Page, "general/tcl.html")
+	assert.Equal(t, general.Packages[1].Name, "tcl")
+	assert.Equal(t, general.Packages[1].Version, "8.6.9")
+
+	yml, err := catalog.ToYAML()
+	assert.Assert(t, is.Nil(err))
+	file := filepath.Join(dir, "catalog.yaml")
+	err = ioutil.WriteFile(file, yml, 0644)
+	assert.Assert(t, is.Nil(err))
+	pkgs, err := LoadPackages(file, "")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(pkgs), 2)
+	assert.Equal(t, pkgs[0].Name, "zlib")
+	assert.DeepEqual(t, pkgs[1].Dependencies.Requires, []string{"zlib-1.2.11"})
+	fmt.Printf("%s\n", yml)
+}
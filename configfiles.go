@@ -0,0 +1,152 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ConfigFile struct for configfile
+// A configuration file named by the page. Files a command creates from a
+// here-document hold its Content and the index of the Command; Append is set
+// when the command adds to the file. Command is -1 for files only named in the
+// page's text.
+type ConfigFile struct {
+	Path    string `json:"path" yaml:"path"`
+	Content string `json:"content,omitempty" yaml:"content,omitempty"`
+	Append  bool   `json:"append,omitempty" yaml:"append,omitempty"`
+	Command int    `json:"command" yaml:"command"`
+}
+
+// Bootscript struct for bootscript
+// A boot script or unit installed with make install-<name>, from the
+// blfs-bootscripts or blfs-systemd-units package named in Package.
+type Bootscript struct {
+	Name    string `json:"name" yaml:"name"`
+	Package string `json:"package" yaml:"package"`
+	Command int    `json:"command" yaml:"command"`
+}
+
+// bootscriptPackages are the packages holding the book's boot scripts and units
+var bootscriptPackages = []string{"blfs-systemd-units", "blfs-bootscripts"}
+
+// bootscriptTarget matches the make targets installing a boot script or unit
+var bootscriptTarget = regexp.MustCompile(`^install-([A-Za-z0-9._-]+)$`)
+
+// isConfigPath func takes p string input and returns bool
+func isConfigPath(p string) bool {
+	switch {
+	case strings.HasPrefix(p, "/dev/"), strings.HasSuffix(p, "/"):
+		return false
+	case strings.HasPrefix(p, "/"), strings.HasPrefix(p, "~/"), strings.HasPrefix(p, "$HOME/"):
+		return true
+	}
+	return false
+}
+
+// heredocFiles func takes cmds []Command input and returns []ConfigFile
+// Files written by commands such as cat > /etc/foo.conf << "EOF".
+func heredocFiles(cmds []Command) []ConfigFile {
+	files := make([]ConfigFile, 0)
+	for _, cmd := range cmds {
+		steps, _, err := ParseSteps(cmd, ".")
+		if err != nil {
+			continue
+		}
+		for _, step := range steps {
+			var (
+				file    ConfigFile
+				heredoc bool
+			)
+			for _, r := range step.Redirects {
+				switch r.Op {
+				case ">", ">|", ">>":
+					file.Path, file.Append = r.Target, r.Op == ">>"
+				case "<<", "<<-":
+					file.Content, heredoc = r.Heredoc, true
+				}
+			}
+			if heredoc && isConfigPath(file.Path) {
+				file.Command = cmd.Index
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// ExtractConfigFiles func takes doc *goquery.Document, cmds []Command input and returns []ConfigFile
+// Files created from here-documents come first, in command order, followed by
+// the other files named in the Configuring section.
+func ExtractConfigFiles(doc *goquery.Document, cmds []Command) []ConfigFile {
+	files := heredocFiles(cmds)
+	seen := make(map[string]bool)
+	for _, file := range files {
+		seen[file.Path] = true
+	}
+	doc.Find(".configuration .filename").Each(func(i int, s *goquery.Selection) {
+		p := collapse(s.Text())
+		if !isConfigPath(p) || seen[p] {
+			return
+		}
+		seen[p] = true
+		files = append(files, ConfigFile{Path: p, Command: -1})
+	})
+	return files
+}
+
+// ExtractBootscripts func takes doc *goquery.Document, cmds []Command input and returns []Bootscript
+// Boot scripts are installed by make install-<name>, run in a
+// blfs-bootscripts or blfs-systemd-units directory, or in the Configuring
+// section from the package the section names.
+func ExtractBootscripts(doc *goquery.Document, cmds []Command) []Bootscript {
+	text := doc.Find(".configuration").Text()
+	pkg := ""
+	for _, name := range bootscriptPackages {
+		if strings.Contains(text, name) {
+			pkg = name
+			break
+		}
+	}
+	return commandBootscripts(cmds, pkg)
+}
+
+// commandBootscripts func takes cmds []Command, pkg string input and returns []Bootscript
+// pkg is the package named by the Configuring section, used for the make
+// install-<name> steps there that do not run in a boot scripts directory.
+func commandBootscripts(cmds []Command, pkg string) []Bootscript {
+	scripts := make([]Bootscript, 0)
+	for _, cmd := range cmds {
+		steps, _, err := ParseSteps(cmd, ".")
+		if err != nil {
+			continue
+		}
+		for _, step := range steps {
+			if step.Name() != "make" {
+				continue
+			}
+			stepPkg := ""
+			for _, name := range bootscriptPackages {
+				if strings.Contains(step.Dir, name) {
+					stepPkg = name
+				}
+			}
+			if stepPkg == "" && cmd.Section == "configuration" {
+				stepPkg = pkg
+			}
+			if stepPkg == "" {
+				continue
+			}
+			for _, arg := range step.Argv[1:] {
+				if m := bootscriptTarget.FindStringSubmatch(arg); m != nil {
+					scripts = append(scripts, Bootscript{Name: m[1], Package: stepPkg, Command: cmd.Index})
+				}
+			}
+		}
+	}
+	return scripts
+}
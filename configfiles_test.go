@@ -0,0 +1,75 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// configPage is a page with a Configuring section
+const configPage = `<html><body>
+<div class="installation">
+<pre class="root"><kbd class="command">make install &amp;&amp;
+cat &gt; /usr/share/foo/defaults &lt;&lt; "EOF"
+mode=fast
+EOF</kbd></pre>
+</div>
+<div class="configuration">
+<h2 class="sect2">Configuring Foo</h2>
+<h3>Config Files</h3>
+<p><code class="filename">/etc/foo.conf</code>, <code class="filename">~/.foorc</code>
+and <code class="filename">/etc/foo.d/</code></p>
+<pre class="root"><kbd class="command">cat &gt; /etc/foo.conf &lt;&lt; "EOF"
+# Begin /etc/foo.conf
+listen = 127.0.0.1
+# End /etc/foo.conf
+EOF
+cat &gt;&gt; /etc/shells &lt;&lt;- EOF
+	/bin/foosh
+	EOF
+echo done &gt; /dev/null</kbd></pre>
+<h3>Boot Script</h3>
+<p>Install the <code class="filename">/etc/rc.d/init.d/foo</code> init script
+included in the <a class="xref" href="../introduction/bootscripts.html">blfs-bootscripts-20191204</a>
+package.</p>
+<pre class="root"><kbd class="command">make install-foo</kbd></pre>
+</div>
+</body></html>`
+
+// TestExtractConfigFiles func takes no input and returns t *testing.T
+func TestExtractConfigFiles(t *testing.T) {
+	doc, err := ReadDoc([]byte(configPage))
+	assert.Assert(t, is.Nil(err))
+	cmds, err := ExtractCommands(doc)
+	assert.Assert(t, is.Nil(err))
+	files := ExtractConfigFiles(doc, cmds)
+	assert.DeepEqual(t, files, []ConfigFile{
+		{Path: "/usr/share/foo/defaults", Content: "mode=fast\n", Command: 0},
+		{Path: "/etc/foo.conf", Content: "# Begin /etc/foo.conf\nlisten = 127.0.0.1\n# End /etc/foo.conf\n", Command: 1},
+		{Path: "/etc/shells", Content: "/bin/foosh\n", Append: true, Command: 1},
+		{Path: "~/.foorc", Command: -1},
+		{Path: "/etc/rc.d/init.d/foo", Command: -1},
+	})
+}
+
+// TestExtractBootscripts func takes no input and returns t *testing.T
+func TestExtractBootscripts(t *testing.T) {
+	doc, err := ReadDoc([]byte(configPage))
+	assert.Assert(t, is.Nil(err))
+	cmds, err := ExtractCommands(doc)
+	assert.Assert(t, is.Nil(err))
+	assert.DeepEqual(t, ExtractBootscripts(doc, cmds), []Bootscript{
+		{Name: "foo", Package: "blfs-bootscripts", Command: 2},
+	})
+
+	cmds = []Command{{Cmd: "cd blfs-systemd-units-20191026 &&\nmake install-nfs-server", Index: 5}}
+	assert.DeepEqual(t, ExtractBootscripts(doc, cmds), []Bootscript{
+		{Name: "nfs-server", Package: "blfs-systemd-units", Command: 5},
+	})
+	cmds = []Command{{Cmd: "make install-docs", Index: 0, Section: "installation"}}
+	assert.Equal(t, len(ExtractBootscripts(doc, cmds)), 0)
+}
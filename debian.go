@@ -0,0 +1,228 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// debianMaintainer is the placeholder maintainer of generated packages
+const debianMaintainer = "cmdext <cmdext@localhost>"
+
+// debianControlTemplate is the debian/control layout
+const debianControlTemplate = `Source: {{ .PkgName }}
+Section: misc
+Priority: optional
+Maintainer: {{ .Maintainer }}
+Build-Depends: debhelper-compat (= 12){{ range .Depends }}, {{ . }}{{ end }}
+Standards-Version: 4.4.1
+{{ if .URL }}Homepage: {{ .URL }}
+{{ end }}
+Package: {{ .PkgName }}
+Architecture: any
+Depends: ${shlibs:Depends}, ${misc:Depends}{{ range .Depends }}, {{ . }}{{ end }}
+{{ if .Recommends }}Recommends: {{ join .Recommends ", " }}
+{{ end }}{{ if .Suggests }}Suggests: {{ join .Suggests ", " }}
+{{ end }}Description: {{ .Summary }}
+{{ range .LongDescription }} {{ . }}
+{{ end }}`
+
+// debianRulesTemplate is the debian/rules layout
+const debianRulesTemplate = `#!/usr/bin/make -f
+# Generated by cmdext from {{ .Name }}-{{ .Version }}
+
+%:
+	dh $@
+{{ range .Rules }}
+{{ .Target }}:
+{{ if .Script }}	{{ if .Install }}DESTDIR=$(CURDIR)/debian/{{ $.PkgName }} {{ end }}sh debian/cmdext/{{ .Script }}
+{{ end }}{{ end }}`
+
+// debianChangelogTemplate is the debian/changelog layout
+const debianChangelogTemplate = `{{ .PkgName }} ({{ .Version }}-1) UNRELEASED; urgency=medium
+
+  * Generated by cmdext from {{ .Name }}-{{ .Version }}.
+
+ -- {{ .Maintainer }}  {{ .Date }}
+`
+
+// debianWatchTemplate is the debian/watch layout read by uscan
+const debianWatchTemplate = `version=4
+{{ .WatchURL }} {{ .WatchPattern }}
+`
+
+// debianRules maps build stages to debhelper overrides
+var debianRules = map[string]string{
+	StagePrepare: "override_dh_auto_configure",
+	StageBuild:   "override_dh_auto_build",
+	StageCheck:   "override_dh_auto_test",
+	StageInstall: "override_dh_auto_install",
+}
+
+// debianRule struct for debianrule
+// Overrides without a script disable the debhelper default.
+type debianRule struct {
+	Target  string
+	Script  string
+	Install bool
+}
+
+// debianData struct for debiandata
+type debianData struct {
+	*PackageInformation
+	PkgName         string
+	Maintainer      string
+	Date            string
+	Summary         string
+	URL             string
+	LongDescription []string
+	Depends         []string
+	Recommends      []string
+	Suggests        []string
+	WatchURL        string
+	WatchPattern    string
+	Rules           []debianRule
+}
+
+// debianDescription func takes description string input and returns []string
+// The extended description is wrapped at 72 columns.
+func debianDescription(description string) []string {
+	lines := make([]string, 0)
+	line := ""
+	for _, word := range strings.Fields(description) {
+		if line != "" && len(line)+len(word) >= 72 {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// debianWatch func takes pkgInfo *PackageInformation input and returns string, string
+// The directory uscan looks for new releases in and the pattern of their
+// archive names, the package version in the primary archive's name becomes
+// @ANY_VERSION@. Both are empty when the version is not in the name.
+func debianWatch(pkgInfo *PackageInformation) (string, string) {
+	archive := unpackedArchive(pkgInfo)
+	i := strings.Index(archive, pkgInfo.Version)
+	if pkgInfo.Version == "" || i < 0 {
+		return "", ""
+	}
+	url := primarySource(pkgInfo).Archive
+	pattern := regexp.QuoteMeta(archive[:i]) + "@ANY_VERSION@" + regexp.QuoteMeta(archive[i+len(pkgInfo.Version):])
+	return strings.TrimSuffix(url, archive), pattern
+}
+
+// debianScript func takes cmds []Command, dirs map[int]commandDir input and returns []byte
+// debhelper runs the script from the unpacked source, the directory the
+// commands change to is followed from there.
+func debianScript(cmds []Command, dirs map[int]commandDir) []byte {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\nset -e\nsrcdir=$(pwd)\n")
+	texts, _ := stageTexts(cmds, dirs, "$srcdir", ".")
+	for _, text := range texts {
+		b.WriteString("\n" + text + "\n")
+	}
+	return []byte(b.String())
+}
+
+// debianDate func takes date time.Time input and returns string
+// A zero date is the Unix epoch, dpkg rejects the year 1.
+func debianDate(date time.Time) string {
+	if date.IsZero() {
+		date = time.Unix(0, 0)
+	}
+	return date.UTC().Format(time.RFC1123Z)
+}
+
+// EmitDebian func takes pkgInfo *PackageInformation, opts EmitOptions input and returns []EmittedFile, error
+// Commands for each build stage are written to debian/cmdext/<stage>.sh and
+// run from the matching debhelper override in debian/rules. Install steps
+// install into $DESTDIR, which rules sets to the package's staging directory.
+// The changelog is dated opts.Date and debian/watch is written when the
+// version appears in the primary archive's name. A package without a version
+// is an error, one without a description is summarized by its name.
+func EmitDebian(pkgInfo *PackageInformation, opts EmitOptions) ([]EmittedFile, error) {
+	// debian/changelog takes the package version from its first entry
+	if pkgInfo.Version == "" {
+		return nil, fmt.Errorf("failed to emit debian for %s : version is empty", pkgInfo.Name)
+	}
+	data := debianData{
+		PackageInformation: pkgInfo,
+		PkgName:            strings.ToLower(pkgInfo.Name),
+		Maintainer:         debianMaintainer,
+		Date:               debianDate(opts.Date),
+		Summary:            summary(pkgInfo.Description),
+		URL:                homepage(primarySource(pkgInfo).Archive),
+		LongDescription:    debianDescription(pkgInfo.Description),
+		Depends:            dependencyNames(pkgInfo.Dependencies.Requires),
+		Recommends:         dependencyNames(pkgInfo.Dependencies.Recommended),
+		Suggests:           dependencyNames(pkgInfo.Dependencies.Optional),
+	}
+	// a control file without a Description synopsis is invalid
+	if data.Summary == "" {
+		data.Summary = pkgInfo.Name
+	}
+	data.WatchURL, data.WatchPattern = debianWatch(pkgInfo)
+	dir := path.Join(pkgInfo.Name, "debian")
+	files := make([]EmittedFile, 0)
+	stages := stagedStages(pkgInfo.Commands, opts.StagingDir(`"$DESTDIR"`))
+	dirs := commandDirs(pkgInfo.Commands)
+	for _, stage := range Stages {
+		rule := debianRule{Target: debianRules[stage], Install: stage == StageInstall}
+		if len(stages[stage]) > 0 {
+			rule.Script = stage + ".sh"
+			files = append(files, EmittedFile{
+				Path:    path.Join(dir, "cmdext", rule.Script),
+				Content: debianScript(stages[stage], dirs),
+				Mode:    0755,
+			})
+		}
+		data.Rules = append(data.Rules, rule)
+	}
+	for _, f := range []struct {
+		name string
+		text string
+		mode os.FileMode
+	}{
+		{"control", debianControlTemplate, 0644},
+		{"rules", debianRulesTemplate, 0755},
+		{"changelog", debianChangelogTemplate, 0644},
+		{"watch", debianWatchTemplate, 0644},
+	} {
+		if f.name == "watch" && data.WatchPattern == "" {
+			continue
+		}
+		tmpl, err := ParseTemplate(f.name, f.text)
+		if err != nil {
+			return nil, err
+		}
+		content, err := Render(tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, EmittedFile{Path: path.Join(dir, f.name), Content: content, Mode: f.mode})
+	}
+	files = append(files, EmittedFile{Path: path.Join(dir, "source", "format"), Content: []byte("3.0 (quilt)\n"), Mode: 0644})
+	return files, nil
+}
+
+func init() {
+	RegisterEmitter(Emitter{
+		Name: "debian",
+		Emit: EmitDebian,
+	})
+}
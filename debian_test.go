@@ -0,0 +1,87 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestEmitDebian func takes no input and returns t *testing.T
+func TestEmitDebian(t *testing.T) {
+	date := time.Date(2019, 9, 1, 12, 0, 0, 0, time.UTC)
+	files, err := EmitDebian(tclPackage(), EmitOptions{Date: date})
+	assert.Assert(t, is.Nil(err))
+	byPath := make(map[string]EmittedFile)
+	for _, file := range files {
+		fmt.Printf("# ==> %s <==\n%s\n", file.Path, file.Content)
+		byPath[file.Path] = file
+	}
+	assert.Equal(t, len(files), 9)
+
+	control := string(byPath["tcl/debian/control"].Content)
+	assert.Assert(t, strings.Contains(control, "Source: tcl\n"))
+	assert.Assert(t, strings.Contains(control, "Build-Depends: debhelper-compat (= 12), zlib\n"))
+	assert.Assert(t, strings.Contains(control, "Depends: ${shlibs:Depends}, ${misc:Depends}, zlib\nRecommends: tk\nSuggests: doxygen\n"))
+	assert.Assert(t, strings.Contains(control, "Description: The Tcl package contains the Tool Command Language, a robust general-purpose scripting language\n The Tcl package"))
+
+	rules := byPath["tcl/debian/rules"]
+	assert.Equal(t, rules.Mode, os.FileMode(0755))
+	assert.Assert(t, strings.Contains(string(rules.Content), "override_dh_auto_test:\n\tsh debian/cmdext/check.sh\n"))
+	assert.Assert(t, strings.Contains(string(rules.Content), "override_dh_auto_install:\n\tDESTDIR=$(CURDIR)/debian/tcl sh debian/cmdext/install.sh\n"))
+
+	install := string(byPath["tcl/debian/cmdext/install.sh"].Content)
+	assert.Assert(t, strings.HasPrefix(install, "#!/bin/sh\nset -e\nsrcdir=$(pwd)\n"))
+	assert.Assert(t, strings.Contains(install, "cd \"$srcdir/unix\"\n\nmake DESTDIR=\"$DESTDIR\" install &&"))
+	assert.Assert(t, !strings.Contains(string(byPath["tcl/debian/cmdext/build.sh"].Content), "cd \"$srcdir"))
+
+	changelog := string(byPath["tcl/debian/changelog"].Content)
+	assert.Assert(t, strings.HasPrefix(changelog, "tcl (8.6.9-1) UNRELEASED; urgency=medium\n"))
+	assert.Assert(t, strings.HasSuffix(changelog, " -- cmdext <cmdext@localhost>  Sun, 01 Sep 2019 12:00:00 +0000\n"))
+	again, err := EmitDebian(tclPackage(), EmitOptions{Date: date})
+	assert.Assert(t, is.Nil(err))
+	assert.DeepEqual(t, again, files)
+	assert.Equal(t, string(byPath["tcl/debian/watch"].Content), "version=4\nhttps://downloads.sourceforge.net/tcl/ tcl@ANY_VERSION@-src\\.tar\\.gz\n")
+	assert.Equal(t, string(byPath["tcl/debian/source/format"].Content), "3.0 (quilt)\n")
+}
+
+// TestEmitDebianEmpty func takes no input and returns t *testing.T
+func TestEmitDebianEmpty(t *testing.T) {
+	files, err := EmitDebian(&PackageInformation{Name: "foo", Version: "1.0"}, EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	control := ""
+	for _, file := range files {
+		if file.Path == "foo/debian/control" {
+			control = string(file.Content)
+		}
+	}
+	assert.Assert(t, strings.HasSuffix(control, "Description: foo\n"), control)
+	_, err = EmitDebian(&PackageInformation{Name: "foo", Description: "The foo package."}, EmitOptions{})
+	assert.ErrorContains(t, err, "failed to emit debian for foo : version is empty")
+}
+
+// TestDebianDescription func takes no input and returns t *testing.T
+func TestDebianDescription(t *testing.T) {
+	lines := debianDescription(strings.Repeat("word ", 30))
+	assert.Equal(t, len(lines), 3)
+	for _, line := range lines {
+		assert.Assert(t, len(line) < 72)
+	}
+}
+
+// TestDebianWatch func takes no input and returns t *testing.T
+func TestDebianWatch(t *testing.T) {
+	url, pattern := debianWatch(&PackageInformation{Version: "1.2.11", Sources: []Source{{Archive: "https://zlib.net/zlib-1.2.11.tar.xz"}}})
+	assert.Equal(t, url, "https://zlib.net/")
+	assert.Equal(t, pattern, `zlib-@ANY_VERSION@\.tar\.xz`)
+	_, pattern = debianWatch(&PackageInformation{Version: "2019", Sources: []Source{{Archive: "https://example.com/snapshot.tar.gz"}}})
+	assert.Equal(t, pattern, "")
+	assert.Equal(t, debianDate(time.Time{}), "Thu, 01 Jan 1970 00:00:00 +0000")
+}
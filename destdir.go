@@ -0,0 +1,418 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// shellWord struct for shellword
+// Pos is the byte offset of the word in the command text and Op marks
+// redirection operators.
+type shellWord struct {
+	Text string
+	Pos  int
+	Op   bool
+}
+
+// destdirEdit struct for destdiredit
+type destdirEdit struct {
+	pos  int
+	text string
+}
+
+var (
+	// assignmentWord matches a leading variable assignment
+	assignmentWord = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=`)
+	// absolutePath matches absolute paths and paths below a variable such as $XORG_PREFIX
+	absolutePath = regexp.MustCompile(`^(/|\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?/)`)
+	// pythonCommand matches python interpreters
+	pythonCommand = regexp.MustCompile(`^python[0-9.]*$`)
+	// pipCommand matches pip
+	pipCommand = regexp.MustCompile(`^pip[0-9.]*$`)
+)
+
+// buildTreeVars hold paths that are not below the installed system
+var buildTreeVars = map[string]bool{"PWD": true, "OLDPWD": true, "HOME": true, "SRCDIR": true, "TMPDIR": true}
+
+// volatileDirs are absolute paths that are never staged
+var volatileDirs = []string{"/dev/", "/proc/", "/sys/", "/tmp/"}
+
+// stagedOptions lists the short options taking a value of the commands whose operands are staged
+var stagedOptions = map[string]string{
+	"install": "mogtS",
+	"cp":      "tS",
+	"mv":      "tS",
+	"ln":      "tS",
+	"mkdir":   "m",
+}
+
+// systemWriters write to the paths they are given
+var systemWriters = map[string]bool{
+	"chmod": true, "chown": true, "chgrp": true, "rm": true, "rmdir": true,
+	"touch": true, "unlink": true, "tee": true, "install-info": true,
+}
+
+// systemChangers change the running system whatever their arguments
+var systemChangers = map[string]bool{
+	"useradd": true, "usermod": true, "userdel": true, "groupadd": true,
+	"groupmod": true, "groupdel": true, "passwd": true, "systemctl": true,
+}
+
+// unquote func takes word string input and returns string
+// Quote characters are dropped, which is enough to look at paths and options.
+func unquote(word string) string {
+	return strings.NewReplacer(`"`, "", `'`, "", `\`, "").Replace(word)
+}
+
+// simpleCommands func takes cmd string input and returns [][]shellWord, error
+// The words of every simple command in cmd, with each redirection as an
+// operator word followed by its target.
+func simpleCommands(cmd string) ([][]shellWord, error) {
+	file, err := parseShell(cmd)
+	if err != nil {
+		return nil, err
+	}
+	cmds := make([][]shellWord, 0)
+	walkShell(cmd, file.Stmts, ".", func(s *syntax.Stmt, dir string) {
+		call, ok := s.Cmd.(*syntax.CallExpr)
+		if !ok {
+			return
+		}
+		words := make([]shellWord, 0, len(call.Assigns)+len(call.Args)+2*len(s.Redirs))
+		for _, a := range call.Assigns {
+			words = append(words, shellWord{Text: nodeText(cmd, a), Pos: int(a.Pos().Offset())})
+		}
+		for _, w := range call.Args {
+			words = append(words, shellWord{Text: nodeText(cmd, w), Pos: int(w.Pos().Offset())})
+		}
+		for _, r := range s.Redirs {
+			words = append(words, shellWord{Text: r.Op.String(), Pos: int(r.OpPos.Offset()), Op: true})
+			if r.Word != nil {
+				words = append(words, shellWord{Text: nodeText(cmd, r.Word), Pos: int(r.Word.Pos().Offset())})
+			}
+		}
+		cmds = append(cmds, words)
+	})
+	return cmds, nil
+}
+
+// isStagedPath func takes word, destdir string input and returns bool
+// Absolute paths outside of the build tree that are not staged yet.
+func isStagedPath(word, destdir string) bool {
+	w := unquote(word)
+	if strings.HasPrefix(w, unquote(destdir)) {
+		return false
+	}
+	m := absolutePath.FindStringSubmatch(w)
+	if m == nil {
+		return false
+	}
+	if m[2] != "" {
+		return !buildTreeVars[m[2]] && m[2] != "DESTDIR" && m[2] != "pkgdir"
+	}
+	for _, dir := range volatileDirs {
+		if strings.HasPrefix(w+"/", dir) {
+			return false
+		}
+	}
+	return true
+}
+
+// stagedOperands func takes name string, args []shellWord input and returns []destdirEdit
+// The destinations of install, cp, mv, ln and mkdir are found from their
+// options and operands and the absolute ones are moved below destdir, as are
+// the absolute sources of cp and mv.
+func stagedOperands(name string, args []shellWord, destdir string) []destdirEdit {
+	var (
+		operands []shellWord
+		targets  []shellWord
+		dirMode  = name == "mkdir"
+	)
+	valueOpts := stagedOptions[name]
+	for j := 0; j < len(args); j++ {
+		w := unquote(args[j].Text)
+		switch {
+		case w == "--":
+			operands = append(operands, args[j+1:]...)
+			j = len(args)
+		case strings.HasPrefix(w, "--target-directory="):
+			eq := strings.IndexByte(args[j].Text, '=')
+			targets = append(targets, shellWord{Text: args[j].Text[eq+1:], Pos: args[j].Pos + eq + 1})
+		case w == "--directory":
+			dirMode = true
+		case w == "--target-directory" && j+1 < len(args):
+			j++
+			targets = append(targets, args[j])
+		case w == "--mode" || w == "--owner" || w == "--group" || w == "--suffix":
+			j++
+		case strings.HasPrefix(w, "--"):
+		case strings.HasPrefix(w, "-") && len(w) > 1:
+			for k := 1; k < len(w); k++ {
+				if name == "install" && w[k] == 'd' {
+					dirMode = true
+				}
+				if strings.IndexByte(valueOpts, w[k]) < 0 {
+					continue
+				}
+				if k < len(w)-1 {
+					if w[k] == 't' && w == args[j].Text {
+						targets = append(targets, shellWord{Text: w[k+1:], Pos: args[j].Pos + k + 1})
+					}
+				} else if j+1 < len(args) {
+					j++
+					if w[k] == 't' {
+						targets = append(targets, args[j])
+					}
+				}
+				break
+			}
+		default:
+			operands = append(operands, args[j])
+		}
+	}
+	var sources []shellWord
+	switch {
+	case len(targets) > 0:
+		sources = operands
+	case dirMode:
+		targets = operands
+	case len(operands) >= 2:
+		sources = operands[:len(operands)-1]
+		targets = operands[len(operands)-1:]
+	}
+	if name == "cp" || name == "mv" {
+		// absolute sources are files the install step put there, which are
+		// below destdir once staged
+		targets = append(targets, sources...)
+	}
+	edits := make([]destdirEdit, 0, len(targets))
+	for _, target := range targets {
+		if isStagedPath(target.Text, destdir) {
+			edits = append(edits, destdirEdit{pos: target.Pos, text: destdir})
+		}
+	}
+	return edits
+}
+
+// sedFiles func takes args []shellWord input and returns []shellWord
+// The files sed edits in place, none when it does not edit in place.
+func sedFiles(args []shellWord) []shellWord {
+	var (
+		files   []shellWord
+		inPlace bool
+		script  bool
+	)
+	for j := 0; j < len(args); j++ {
+		w := unquote(args[j].Text)
+		switch {
+		case w == "-e" || w == "-f" || w == "--expression" || w == "--file":
+			script = true
+			j++
+		case strings.HasPrefix(w, "--expression=") || strings.HasPrefix(w, "--file="):
+			script = true
+		case strings.HasPrefix(w, "--in-place"):
+			inPlace = true
+		case strings.HasPrefix(w, "--"):
+		case strings.HasPrefix(w, "-") && len(w) > 1:
+			for k := 1; k < len(w); k++ {
+				if w[k] == 'i' {
+					inPlace = true
+					break
+				}
+				if w[k] == 'e' || w[k] == 'f' {
+					script = true
+					if k == len(w)-1 {
+						j++
+					}
+					break
+				}
+			}
+		default:
+			files = append(files, args[j])
+		}
+	}
+	if !inPlace {
+		return nil
+	}
+	if !script && len(files) > 0 {
+		files = files[1:]
+	}
+	return files
+}
+
+// stageSimpleCommand func takes words []shellWord, destdir string input and returns []destdirEdit, []string
+// Known install idioms are rewritten to install into destdir. Commands that
+// write outside of destdir otherwise are returned as issues, as is a cd into
+// the system since the relative paths that follow it are not staged.
+func stageSimpleCommand(words []shellWord, destdir string) ([]destdirEdit, []string) {
+	var (
+		edits  []destdirEdit
+		issues []string
+		args   []shellWord
+	)
+	for j := 0; j < len(words); j++ {
+		if !words[j].Op {
+			args = append(args, words[j])
+			continue
+		}
+		if j+1 < len(words) && !words[j+1].Op {
+			j++
+			op := words[j-1].Text
+			if strings.HasPrefix(op, ">") && op != ">&" && isStagedPath(words[j].Text, destdir) {
+				issues = append(issues, fmt.Sprintf("redirects output to %s", unquote(words[j].Text)))
+			}
+		}
+	}
+	for len(args) > 0 {
+		w := unquote(args[0].Text)
+		if m := assignmentWord.FindStringSubmatch(w); m != nil {
+			if m[1] == "DESTDIR" {
+				return nil, nil
+			}
+		} else if w != "sudo" && w != "env" && w != "as_root" && !strings.HasPrefix(w, "-") {
+			break
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil, issues
+	}
+	name := path.Base(unquote(args[0].Text))
+	rest := args[1:]
+	restText := make([]string, len(rest))
+	for j, arg := range rest {
+		restText[j] = unquote(arg.Text)
+	}
+	indexOf := func(s string) int {
+		for j, text := range restText {
+			if text == s {
+				return j
+			}
+		}
+		return -1
+	}
+	switch {
+	case name == "make":
+		for _, text := range restText {
+			if strings.HasPrefix(text, "DESTDIR=") {
+				return nil, issues
+			}
+		}
+		for _, text := range restText {
+			if text == "install" || strings.HasPrefix(text, "install-") {
+				edits = append(edits, destdirEdit{pos: args[0].Pos + len(args[0].Text), text: " DESTDIR=" + destdir})
+				break
+			}
+		}
+	case name == "ninja" || name == "samu":
+		if indexOf("install") >= 0 {
+			edits = append(edits, destdirEdit{pos: args[0].Pos, text: "DESTDIR=" + destdir + " "})
+		}
+	case name == "meson":
+		if len(restText) > 0 && restText[0] == "install" {
+			edits = append(edits, destdirEdit{pos: args[0].Pos, text: "DESTDIR=" + destdir + " "})
+		}
+	case pythonCommand.MatchString(name) || pipCommand.MatchString(name):
+		i := indexOf("install")
+		staged := (indexOf("setup.py") >= 0 || indexOf("pip") >= 0 || pipCommand.MatchString(name)) && i >= 0
+		for _, text := range restText {
+			if strings.HasPrefix(text, "--root") {
+				staged = false
+			}
+		}
+		if staged {
+			edits = append(edits, destdirEdit{pos: rest[i].Pos + len(rest[i].Text), text: " --root=" + destdir})
+		}
+	case stagedOptions[name] != "":
+		edits = append(edits, stagedOperands(name, rest, destdir)...)
+	case name == "cd" || name == "pushd":
+		for j, text := range restText {
+			if !strings.HasPrefix(text, "-") && isStagedPath(rest[j].Text, destdir) {
+				issues = append(issues, fmt.Sprintf("%s into %s", name, text))
+			}
+		}
+	case systemChangers[name]:
+		issues = append(issues, fmt.Sprintf("%s changes the running system", name))
+	case name == "sed":
+		for _, file := range sedFiles(rest) {
+			if isStagedPath(file.Text, destdir) {
+				issues = append(issues, fmt.Sprintf("sed edits %s", unquote(file.Text)))
+			}
+		}
+	case systemWriters[name] || name == "tar":
+		for j, text := range restText {
+			if strings.HasPrefix(text, "-") || !isStagedPath(rest[j].Text, destdir) {
+				continue
+			}
+			if name == "tar" && (j == 0 || (restText[j-1] != "-C" && restText[j-1] != "--directory")) {
+				continue
+			}
+			issues = append(issues, fmt.Sprintf("%s writes to %s", name, text))
+		}
+	}
+	return edits, issues
+}
+
+// RewriteDestdir func takes cmd, destdir string input and returns string, []string
+// Install steps are rewritten to install into destdir: make, ninja and meson
+// install get DESTDIR, python setup.py and pip install get --root, and the
+// absolute destinations of install, cp, mv, ln and mkdir are moved below
+// destdir. The returned issues describe writes outside of destdir that could
+// not be rewritten.
+func RewriteDestdir(cmd, destdir string) (string, []string) {
+	var (
+		edits  []destdirEdit
+		issues []string
+	)
+	cmds, err := simpleCommands(cmd)
+	if err != nil {
+		return cmd, []string{err.Error()}
+	}
+	for _, words := range cmds {
+		e, i := stageSimpleCommand(words, destdir)
+		edits = append(edits, e...)
+		issues = append(issues, i...)
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].pos > edits[j].pos
+	})
+	for _, edit := range edits {
+		cmd = cmd[:edit.pos] + edit.text + cmd[edit.pos:]
+	}
+	return cmd, issues
+}
+
+// StageCommands func takes cmds []Command, destdir string input and returns []Command
+// Install stage and root commands are rewritten to install into destdir and
+// list what could not be rewritten in StagingIssues. Other commands build in
+// the source tree and are returned as they are.
+func StageCommands(cmds []Command, destdir string) []Command {
+	staged := make([]Command, 0, len(cmds))
+	for i, stage := range commandStages(cmds) {
+		cmd := cmds[i]
+		if stage == StageInstall || cmd.Root {
+			cmd.Cmd, cmd.StagingIssues = RewriteDestdir(cmd.Cmd, destdir)
+		}
+		staged = append(staged, cmd)
+	}
+	return staged
+}
+
+// stagedText func takes cmd Command input and returns string
+// Staging issues are shown as comments ahead of the command.
+func stagedText(cmd Command) string {
+	var b strings.Builder
+	for _, issue := range cmd.StagingIssues {
+		b.WriteString("# cmdext: not staged, " + issue + "\n")
+	}
+	b.WriteString(strings.TrimSpace(cmd.Cmd))
+	return b.String()
+}
@@ -0,0 +1,90 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestRewriteDestdir func takes no input and returns t *testing.T
+func TestRewriteDestdir(t *testing.T) {
+	tests := []struct {
+		cmd    string
+		want   string
+		issues int
+	}{
+		{"make install", `make DESTDIR="$D" install`, 0},
+		{"make -j1 install-private-headers", `make DESTDIR="$D" -j1 install-private-headers`, 0},
+		{"make DESTDIR=/tmp/x install", "make DESTDIR=/tmp/x install", 0},
+		{"ninja install", `DESTDIR="$D" ninja install`, 0},
+		{"meson install -C build", `DESTDIR="$D" meson install -C build`, 0},
+		{"python3 setup.py install --optimize=1", `python3 setup.py install --root="$D" --optimize=1`, 0},
+		{"pip3 install --no-index foo", `pip3 install --root="$D" --no-index foo`, 0},
+		{"install -v -m755 -d /usr/share/doc/foo-1.0", `install -v -m755 -d "$D"/usr/share/doc/foo-1.0`, 0},
+		{"install -v -dm755 /etc/foo /usr/lib/foo", `install -v -dm755 "$D"/etc/foo "$D"/usr/lib/foo`, 0},
+		{"install -v -m644 doc/*.html /usr/share/doc/foo", `install -v -m644 doc/*.html "$D"/usr/share/doc/foo`, 0},
+		{"install -m644 -t /usr/share/man/man1 foo.1", `install -m644 -t "$D"/usr/share/man/man1 foo.1`, 0},
+		{"cp -v -R doc/* /usr/share/doc/foo", `cp -v -R doc/* "$D"/usr/share/doc/foo`, 0},
+		{"cp --target-directory=/usr/bin foo", `cp --target-directory="$D"/usr/bin foo`, 0},
+		{"ln -sfv libfoo.so.1 /usr/lib/libfoo.so", `ln -sfv libfoo.so.1 "$D"/usr/lib/libfoo.so`, 0},
+		{"ln -sv ../../lib/libfoo.so.1 $XORG_PREFIX/lib", `ln -sv ../../lib/libfoo.so.1 "$D"$XORG_PREFIX/lib`, 0},
+		{"mkdir -pv /etc/foo", `mkdir -pv "$D"/etc/foo`, 0},
+		{"mv -v /usr/lib/libfoo.so.* /lib", `mv -v "$D"/usr/lib/libfoo.so.* "$D"/lib`, 0},
+		{"mv -t /usr/bin /usr/sbin/foo", `mv -t "$D"/usr/bin "$D"/usr/sbin/foo`, 0},
+		{"cp /usr/share/foo/foo.conf /etc/foo.conf", `cp "$D"/usr/share/foo/foo.conf "$D"/etc/foo.conf`, 0},
+		{"cd /usr/lib && ln -sf libfoo.so.1 libfoo.so", "cd /usr/lib && ln -sf libfoo.so.1 libfoo.so", 1},
+		{"cd build && make install", `cd build && make DESTDIR="$D" install`, 0},
+		{"make installcheck", "make installcheck", 0},
+		{"make install-strip", `make DESTDIR="$D" install-strip`, 0},
+		{"cp foo.conf $PWD/build", "cp foo.conf $PWD/build", 0},
+		{`ln -sf "$D"/usr/lib/a /usr/lib/b`, `ln -sf "$D"/usr/lib/a "$D"/usr/lib/b`, 0},
+		{"sudo make install", `sudo make DESTDIR="$D" install`, 0},
+		{"chown -R root:root /usr/share/doc/foo", "chown -R root:root /usr/share/doc/foo", 1},
+		{"sed -i 's/foo/bar/' Makefile", "sed -i 's/foo/bar/' Makefile", 0},
+		{"sed -i '/^#/d' /etc/foo.conf", "sed -i '/^#/d' /etc/foo.conf", 1},
+		{"sed '/^#/d' /etc/foo.conf > foo.conf", "sed '/^#/d' /etc/foo.conf > foo.conf", 0},
+		{"groupadd -g 18 messagebus", "groupadd -g 18 messagebus", 1},
+		{"tar -xf ../foo-doc.tar.xz -C /usr/share/doc", "tar -xf ../foo-doc.tar.xz -C /usr/share/doc", 1},
+		{"make check 2>&1 | tee check.log", "make check 2>&1 | tee check.log", 0},
+		{"echo done > /dev/null", "echo done > /dev/null", 0},
+	}
+	for _, test := range tests {
+		got, issues := RewriteDestdir(test.cmd, `"$D"`)
+		assert.Equal(t, got, test.want, test.cmd)
+		assert.Equal(t, len(issues), test.issues, test.cmd)
+	}
+}
+
+// TestRewriteDestdirMultiline func takes no input and returns t *testing.T
+func TestRewriteDestdirMultiline(t *testing.T) {
+	cmd := "make install &&\n\ninstall -v -m755 -d /usr/share/doc/foo \\\n        /usr/share/doc/foo/api &&\n" +
+		"cat > /etc/foo.conf << \"EOF\"\n# install into /usr\ncp a /usr/bin\nEOF\nln -sv foo /usr/bin/bar"
+	got, issues := RewriteDestdir(cmd, "/stage")
+	assert.Equal(t, got, "make DESTDIR=/stage install &&\n\ninstall -v -m755 -d /stage/usr/share/doc/foo \\\n        /stage/usr/share/doc/foo/api &&\n"+
+		"cat > /etc/foo.conf << \"EOF\"\n# install into /usr\ncp a /usr/bin\nEOF\nln -sv foo /stage/usr/bin/bar")
+	assert.DeepEqual(t, issues, []string{"redirects output to /etc/foo.conf"})
+}
+
+// TestStageCommands func takes no input and returns t *testing.T
+func TestStageCommands(t *testing.T) {
+	cmds := StageCommands([]Command{
+		{Cmd: "sed -i 's@/usr/share@/opt@' Makefile.in", Index: 0},
+		{Cmd: "./configure --prefix=/usr &&\nmake &&\ninstall -m644 tool.conf /usr/share/tool", Index: 2},
+		{Cmd: "make install &&\nuseradd -c foo foo", Index: 1, Root: true},
+	}, "/stage")
+	assert.Equal(t, cmds[0].Cmd, "sed -i 's@/usr/share@/opt@' Makefile.in")
+	assert.Equal(t, cmds[1].Cmd, "./configure --prefix=/usr &&\nmake &&\ninstall -m644 tool.conf /usr/share/tool")
+	assert.Assert(t, is.Len(cmds[1].StagingIssues, 0))
+	cmds = cmds[1:]
+	assert.Equal(t, cmds[1].Cmd, "make DESTDIR=/stage install &&\nuseradd -c foo foo")
+	assert.DeepEqual(t, cmds[1].StagingIssues, []string{"useradd changes the running system"})
+	assert.Equal(t, stagedText(cmds[1]), "# cmdext: not staged, useradd changes the running system\nmake DESTDIR=/stage install &&\nuseradd -c foo foo")
+	opts := EmitOptions{}
+	assert.Equal(t, opts.StagingDir("$pkgdir"), "$pkgdir")
+	opts.Destdir = "/stage"
+	assert.Equal(t, opts.StagingDir("$pkgdir"), "/stage")
+}
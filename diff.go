@@ -0,0 +1,304 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// BookDiff struct for bookdiff
+type BookDiff struct {
+	Added   []PackageSummary `json:"added" yaml:"added"`
+	Removed []PackageSummary `json:"removed" yaml:"removed"`
+	Changed []PackageDiff    `json:"changed" yaml:"changed"`
+}
+
+// PackageSummary struct for packagesummary
+type PackageSummary struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// PackageDiff struct for packagediff
+type PackageDiff struct {
+	Name        string          `json:"name" yaml:"name"`
+	OldVersion  string          `json:"old_version" yaml:"old_version"`
+	NewVersion  string          `json:"new_version" yaml:"new_version"`
+	Downgrade   bool            `json:"downgrade,omitempty" yaml:"downgrade,omitempty"`
+	Requires    ListDiff        `json:"requires" yaml:"requires"`
+	Recommended ListDiff        `json:"recommended" yaml:"recommended"`
+	Optional    ListDiff        `json:"optional" yaml:"optional"`
+	Sources     []SourceDiff    `json:"sources" yaml:"sources"`
+	Commands    []CommandChange `json:"commands" yaml:"commands"`
+}
+
+// ListDiff struct for listdiff
+type ListDiff struct {
+	Added   []string `json:"added" yaml:"added"`
+	Removed []string `json:"removed" yaml:"removed"`
+}
+
+// SourceDiff struct for sourcediff
+type SourceDiff struct {
+	Index      int    `json:"index" yaml:"index"`
+	OldArchive string `json:"old_archive" yaml:"old_archive"`
+	NewArchive string `json:"new_archive" yaml:"new_archive"`
+	OldMD5Sum  string `json:"old_md5sum" yaml:"old_md5sum"`
+	NewMD5Sum  string `json:"new_md5sum" yaml:"new_md5sum"`
+}
+
+// CommandChange struct for commandchange
+type CommandChange struct {
+	Index int    `json:"index" yaml:"index"`
+	Old   string `json:"old" yaml:"old"`
+	New   string `json:"new" yaml:"new"`
+}
+
+// Empty func takes no input and returns bool
+func (l ListDiff) Empty() bool {
+	return len(l.Added) == 0 && len(l.Removed) == 0
+}
+
+// Empty func takes no input and returns bool
+func (d PackageDiff) Empty() bool {
+	return d.OldVersion == d.NewVersion && d.Requires.Empty() && d.Recommended.Empty() &&
+		d.Optional.Empty() && len(d.Sources) == 0 && len(d.Commands) == 0
+}
+
+// diffList func takes old, new []string input and returns ListDiff
+func diffList(old, new []string) ListDiff {
+	diff := ListDiff{Added: []string{}, Removed: []string{}}
+	oldSet := make(map[string]bool, len(old))
+	for _, o := range old {
+		oldSet[o] = true
+	}
+	newSet := make(map[string]bool, len(new))
+	for _, n := range new {
+		newSet[n] = true
+		if !oldSet[n] {
+			diff.Added = append(diff.Added, n)
+		}
+	}
+	for _, o := range old {
+		if !newSet[o] {
+			diff.Removed = append(diff.Removed, o)
+		}
+	}
+	return diff
+}
+
+// diffSources func takes old, new []Source input and returns []SourceDiff
+// Sources are compared by position since archive names change with the version.
+func diffSources(old, new []Source) []SourceDiff {
+	diffs := make([]SourceDiff, 0)
+	n := len(old)
+	if len(new) > n {
+		n = len(new)
+	}
+	for i := 0; i < n; i++ {
+		var o, s Source
+		if i < len(old) {
+			o = old[i]
+		}
+		if i < len(new) {
+			s = new[i]
+		}
+		if o.Archive == s.Archive && o.MD5Sum == s.MD5Sum {
+			continue
+		}
+		diffs = append(diffs, SourceDiff{
+			Index:      i,
+			OldArchive: o.Archive,
+			NewArchive: s.Archive,
+			OldMD5Sum:  o.MD5Sum,
+			NewMD5Sum:  s.MD5Sum,
+		})
+	}
+	return diffs
+}
+
+// diffCommands func takes old, new []Command input and returns []CommandChange
+func diffCommands(old, new []Command) []CommandChange {
+	changes := make([]CommandChange, 0)
+	n := len(old)
+	if len(new) > n {
+		n = len(new)
+	}
+	for i := 0; i < n; i++ {
+		var o, c string
+		if i < len(old) {
+			o = old[i].Cmd
+		}
+		if i < len(new) {
+			c = new[i].Cmd
+		}
+		if strings.TrimSpace(o) == strings.TrimSpace(c) {
+			continue
+		}
+		changes = append(changes, CommandChange{Index: i, Old: o, New: c})
+	}
+	return changes
+}
+
+// DiffPackage func takes old, new *PackageInformation input and returns PackageDiff
+func DiffPackage(old, new *PackageInformation) PackageDiff {
+	return PackageDiff{
+		Name:        new.Name,
+		OldVersion:  old.Version,
+		NewVersion:  new.Version,
+		Downgrade:   CompareVersions(old.Version, new.Version) > 0,
+		Requires:    diffList(dependencyNames(old.Dependencies.Requires), dependencyNames(new.Dependencies.Requires)),
+		Recommended: diffList(dependencyNames(old.Dependencies.Recommended), dependencyNames(new.Dependencies.Recommended)),
+		Optional:    diffList(dependencyNames(old.Dependencies.Optional), dependencyNames(new.Dependencies.Optional)),
+		Sources:     diffSources(old.Sources, new.Sources),
+		Commands:    diffCommands(old.Commands, new.Commands),
+	}
+}
+
+// DiffPackages func takes old, new []*PackageInformation input and returns *BookDiff
+func DiffPackages(old, new []*PackageInformation) *BookDiff {
+	diff := &BookDiff{
+		Added:   []PackageSummary{},
+		Removed: []PackageSummary{},
+		Changed: []PackageDiff{},
+	}
+	oldMap := PackageMap(old)
+	newMap := PackageMap(new)
+	names := make([]string, 0, len(newMap))
+	for name := range newMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n := newMap[name]
+		o, ok := oldMap[name]
+		if !ok {
+			diff.Added = append(diff.Added, PackageSummary{Name: n.Name, Version: n.Version})
+			continue
+		}
+		pkgDiff := DiffPackage(o, n)
+		if !pkgDiff.Empty() {
+			diff.Changed = append(diff.Changed, pkgDiff)
+		}
+	}
+	names = names[:0]
+	for name := range oldMap {
+		if _, ok := newMap[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o := oldMap[name]
+		diff.Removed = append(diff.Removed, PackageSummary{Name: o.Name, Version: o.Version})
+	}
+	return diff
+}
+
+// writeListDiff func takes buf *bytes.Buffer, label string, l ListDiff input and returns
+func writeListDiff(buf *bytes.Buffer, label string, l ListDiff) {
+	if l.Empty() {
+		return
+	}
+	fmt.Fprintf(buf, "    %s:", label)
+	for _, a := range l.Added {
+		fmt.Fprintf(buf, " +%s", a)
+	}
+	for _, r := range l.Removed {
+		fmt.Fprintf(buf, " -%s", r)
+	}
+	fmt.Fprintf(buf, "\n")
+}
+
+// ToText func takes no input and returns []byte
+func (d *BookDiff) ToText() []byte {
+	var buf bytes.Buffer
+	for _, a := range d.Added {
+		fmt.Fprintf(&buf, "+ %s %s\n", a.Name, a.Version)
+	}
+	for _, r := range d.Removed {
+		fmt.Fprintf(&buf, "- %s %s\n", r.Name, r.Version)
+	}
+	for _, c := range d.Changed {
+		switch {
+		case c.Downgrade:
+			fmt.Fprintf(&buf, "~ %s %s -> %s (downgrade)\n", c.Name, c.OldVersion, c.NewVersion)
+		case c.OldVersion != c.NewVersion:
+			fmt.Fprintf(&buf, "~ %s %s -> %s\n", c.Name, c.OldVersion, c.NewVersion)
+		default:
+			fmt.Fprintf(&buf, "~ %s %s\n", c.Name, c.NewVersion)
+		}
+		writeListDiff(&buf, "requires", c.Requires)
+		writeListDiff(&buf, "recommended", c.Recommended)
+		writeListDiff(&buf, "optional", c.Optional)
+		for _, s := range c.Sources {
+			if s.OldArchive != s.NewArchive {
+				fmt.Fprintf(&buf, "    source %d archive: %s -> %s\n", s.Index, s.OldArchive, s.NewArchive)
+			}
+			if s.OldMD5Sum != s.NewMD5Sum {
+				fmt.Fprintf(&buf, "    source %d md5sum: %s -> %s\n", s.Index, s.OldMD5Sum, s.NewMD5Sum)
+			}
+		}
+		for _, cmd := range c.Commands {
+			fmt.Fprintf(&buf, "    command %d changed\n", cmd.Index)
+		}
+	}
+	return buf.Bytes()
+}
+
+// ToPrettyJSON func takes no input and returns []byte, error
+func (d *BookDiff) ToPrettyJSON() ([]byte, error) {
+	content, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to json : %v", err)
+	}
+	return content, nil
+}
+
+// runDiff func takes args []string input and returns error
+func runDiff(args []string) error {
+	var (
+		overrides string
+		asjson    bool
+	)
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&asjson, "json", false, "Output JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s diff [options] <old> <new>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("diff requires an old and a new book or package directory")
+	}
+	old, err := LoadPackages(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	new, err := LoadPackages(flags.Arg(1), overrides)
+	if err != nil {
+		return err
+	}
+	diff := DiffPackages(old, new)
+	if asjson {
+		jsn, err := diff.ToPrettyJSON()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", jsn)
+		return nil
+	}
+	fmt.Printf("%s", diff.ToText())
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// writePackages func takes t *testing.T, pkgs []*PackageInformation input and returns string
+func writePackages(t *testing.T, pkgs ...*PackageInformation) string {
+	dir, err := ioutil.TempDir("", "pkgs")
+	assert.Assert(t, is.Nil(err))
+	for _, pkg := range pkgs {
+		yml, err := pkg.ToYAML()
+		assert.Assert(t, is.Nil(err))
+		err = ioutil.WriteFile(path.Join(dir, pkg.Name+"-"+pkg.Version+".yaml"), yml, 0644)
+		assert.Assert(t, is.Nil(err))
+	}
+	return dir
+}
+
+// TestDiffPackages func takes no input and returns t *testing.T
+func TestDiffPackages(t *testing.T) {
+	oldDir := writePackages(t,
+		&PackageInformation{
+			Name:         "tcl",
+			Version:      "8.6.9",
+			Commands:     []Command{{Cmd: "./configure", Index: 0}, {Cmd: "make", Index: 1}},
+			Dependencies: Dependencies{Requires: []string{"zlib-1.2.11"}, Optional: []string{"tk-8.6.9"}},
+			Sources:      []Source{{Archive: "tcl8.6.9-src.tar.gz", MD5Sum: "aaaa"}},
+		},
+		&PackageInformation{Name: "gone", Version: "1.0"},
+	)
+	defer os.RemoveAll(oldDir)
+	newDir := writePackages(t,
+		&PackageInformation{
+			Name:         "tcl",
+			Version:      "8.6.10",
+			Commands:     []Command{{Cmd: "./configure --enable-64bit", Index: 0}, {Cmd: "make", Index: 1}},
+			Dependencies: Dependencies{Requires: []string{"zlib-1.2.12", "cmake-3.16.0"}},
+			Sources:      []Source{{Archive: "tcl8.6.10-src.tar.gz", MD5Sum: "bbbb"}},
+		},
+		&PackageInformation{Name: "fresh", Version: "0.1"},
+	)
+	defer os.RemoveAll(newDir)
+
+	old, err := LoadPackages(oldDir, "")
+	assert.Assert(t, is.Nil(err))
+	new, err := LoadPackages(newDir, "")
+	assert.Assert(t, is.Nil(err))
+	diff := DiffPackages(old, new)
+	assert.DeepEqual(t, diff.Added, []PackageSummary{{Name: "fresh", Version: "0.1"}})
+	assert.DeepEqual(t, diff.Removed, []PackageSummary{{Name: "gone", Version: "1.0"}})
+	assert.Equal(t, len(diff.Changed), 1)
+	tcl := diff.Changed[0]
+	assert.Equal(t, tcl.OldVersion, "8.6.9")
+	assert.Equal(t, tcl.NewVersion, "8.6.10")
+	assert.DeepEqual(t, tcl.Requires.Added, []string{"cmake"})
+	assert.Equal(t, len(tcl.Requires.Removed), 0)
+	assert.DeepEqual(t, tcl.Optional.Removed, []string{"tk"})
+	assert.Equal(t, len(tcl.Sources), 1)
+	assert.Equal(t, tcl.Sources[0].NewMD5Sum, "bbbb")
+	assert.Equal(t, len(tcl.Commands), 1)
+	assert.Equal(t, tcl.Commands[0].Index, 0)
+
+	unchanged := DiffPackages(old, old)
+	assert.Equal(t, len(unchanged.Changed), 0)
+
+	jsn, err := diff.ToPrettyJSON()
+	assert.Assert(t, is.Nil(err))
+	fmt.Printf("%s\n%s\n", diff.ToText(), jsn)
+}
@@ -0,0 +1,221 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// dockerfileTemplate is the multi-stage Dockerfile layout
+const dockerfileTemplate = `# syntax=docker/dockerfile:1.6
+# Generated by cmdext for {{ join .Targets " " }}
+FROM {{ .Base }} AS cmdext-base
+RUN useradd -m {{ .User }} && install -d -o {{ .User }} /build {{ .Destdir }}
+{{ range $stage := .Stages }}
+# {{ $stage.Package.Name }}-{{ $stage.Package.Version }}
+FROM cmdext-base AS {{ $stage.Name }}
+{{ range $stage.Copies }}COPY --from={{ . }} {{ $.Destdir }}/{{ . }}/ /
+{{ end }}USER {{ $.User }}
+RUN mkdir -p {{ $.Destdir }}/{{ $stage.Name }}
+WORKDIR /build/{{ $stage.Name }}
+{{ range $stage.Sources }}ADD --chown={{ $.User }}{{ if .SHA256 }} --checksum=sha256:{{ .SHA256 }}{{ end }} {{ .Archive }} {{ .File }}
+{{ end }}{{ if $stage.MD5Sums }}RUN md5sum -c - <<'CMDEXT'
+{{ range $stage.MD5Sums }}{{ . }}
+{{ end }}CMDEXT
+{{ end }}{{ if $stage.Primary }}RUN tar -xf {{ $stage.Primary }}
+{{ end }}{{ range $stage.Blocks }}{{ if .User }}USER {{ .User }}
+{{ end }}# {{ .Section }}
+RUN bash -e <<'CMDEXT'
+{{ .Script }}
+CMDEXT
+{{ end }}{{ end }}`
+
+// dockerSource struct for dockersource
+type dockerSource struct {
+	Archive string
+	File    string
+	SHA256  string
+}
+
+// dockerBlock struct for dockerblock
+// User is only set when the block runs as a different user than the one before.
+type dockerBlock struct {
+	Section string
+	User    string
+	Script  string
+}
+
+// dockerStage struct for dockerstage
+type dockerStage struct {
+	Name    string
+	Package *PackageInformation
+	Copies  []string
+	Sources []dockerSource
+	MD5Sums []string
+	Primary string
+	Blocks  []dockerBlock
+}
+
+// dockerfileData struct for dockerfiledata
+type dockerfileData struct {
+	Base    string
+	User    string
+	Destdir string
+	Targets []string
+	Stages  []dockerStage
+}
+
+// DockerfileOptions struct for dockerfileoptions
+// Base is the image every stage starts from and User the unprivileged build user.
+// Each package is staged into a directory named after it below Destdir,
+// /pkg by default.
+type DockerfileOptions struct {
+	EmitOptions
+	Base string
+	User string
+}
+
+// invalidStageChars matches characters not allowed in build stage names
+var invalidStageChars = regexp.MustCompile(`[^a-z0-9._-]+-?`)
+
+// stageName func takes name string input and returns string
+func stageName(name string) string {
+	return invalidStageChars.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// dockerBlocks func takes cmds []Command, destdir, user, base string input and returns []dockerBlock
+// Consecutive commands of the same section run as the same user share a RUN.
+// Every RUN starts by changing to base, the unpacked source, and follows the
+// directory changes of the commands before it. Install steps install into
+// destdir for later stages to copy.
+func dockerBlocks(cmds []Command, destdir, user, base string) []dockerBlock {
+	blocks := make([]dockerBlock, 0)
+	groups := make([][]Command, 0)
+	current := user
+	dirs := commandDirs(cmds)
+	var last *Command
+	cmds = StageCommands(cmds, destdir)
+	for i, cmd := range cmds {
+		if last != nil && last.Section == cmd.Section && last.Root == cmd.Root {
+			groups[len(groups)-1] = append(groups[len(groups)-1], cmd)
+			continue
+		}
+		block := dockerBlock{Section: cmd.Section}
+		if block.Section == "" {
+			block.Section = "commands"
+		}
+		want := user
+		if cmd.Root {
+			want = "root"
+		}
+		if want != current {
+			block.User = want
+			current = want
+		}
+		blocks = append(blocks, block)
+		groups = append(groups, []Command{cmd})
+		last = &cmds[i]
+	}
+	for i, group := range groups {
+		// no directory is known at the start of a RUN so its first command cds
+		texts, _ := stageTexts(group, dirs, base, "")
+		blocks[i].Script = strings.Join(texts, "\n")
+	}
+	return blocks
+}
+
+// EmitDockerfile func takes g *DependencyGraph, targets []string, opts DockerfileOptions input and returns []byte, error
+// There is one build stage per package in dependency order. Each stage copies
+// the installed files of everything it depends on from the earlier stages.
+func EmitDockerfile(g *DependencyGraph, targets []string, opts DockerfileOptions) ([]byte, error) {
+	order := g.Order(targets)
+	if len(order) == 0 {
+		return nil, fmt.Errorf("no known packages in %s", strings.Join(targets, ", "))
+	}
+	destdir := strings.TrimSuffix(opts.StagingDir("/pkg"), "/")
+	data := dockerfileData{Base: opts.Base, User: opts.User, Destdir: destdir, Targets: targets}
+	for _, name := range order {
+		pkgInfo := g.Packages[name]
+		stage := dockerStage{Name: stageName(name), Package: pkgInfo}
+		deps := g.Order([]string{name})
+		for _, dep := range deps[:len(deps)-1] {
+			stage.Copies = append(stage.Copies, stageName(dep))
+		}
+		for _, src := range emitterSources(pkgInfo) {
+			s := dockerSource{
+				Archive: src.Archive,
+				File:    sourceFile(src.Archive),
+				SHA256:  cachedChecksum(opts.SourceCache, src.Archive, sha256.New()),
+			}
+			if s.SHA256 == "" && src.MD5Sum != "" {
+				stage.MD5Sums = append(stage.MD5Sums, src.MD5Sum+"  "+s.File)
+			}
+			stage.Sources = append(stage.Sources, s)
+		}
+		workdir := "/build/" + stage.Name
+		archive := ""
+		if stage.Primary = unpackedArchive(pkgInfo); stage.Primary != "" {
+			archive = `"` + workdir + "/" + doubleQuote(stage.Primary) + `"`
+		}
+		base := workdir
+		if word := sourceDirWord(pkgInfo, archive); word != "." {
+			base += "/" + word
+		}
+		stage.Blocks = dockerBlocks(pkgInfo.Commands, destdir+"/"+stage.Name, opts.User, base)
+		data.Stages = append(data.Stages, stage)
+	}
+	tmpl, err := ParseTemplate("Dockerfile", dockerfileTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return Render(tmpl, data)
+}
+
+// runDockerfile func takes args []string input and returns error
+func runDockerfile(args []string) error {
+	var (
+		opts        DockerfileOptions
+		output      string
+		overrides   string
+		recommended bool
+	)
+	flags := flag.NewFlagSet("dockerfile", flag.ExitOnError)
+	flags.StringVar(&opts.Base, "base", "debian:bookworm", "Base image of every build stage")
+	flags.StringVar(&opts.User, "user", "builder", "Unprivileged user commands run as")
+	flags.StringVar(&opts.SourceCache, "source-cache", "", "Path to a directory of downloaded source archives")
+	flags.StringVar(&opts.Destdir, "destdir", "/pkg", "Directory packages are staged into, one directory per package")
+	flags.StringVar(&output, "output", "", "Path to write the Dockerfile to instead of stdout")
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&recommended, "recommended", false, "Build recommended dependencies too")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s dockerfile [options] <book|catalog|packages> <target>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return fmt.Errorf("dockerfile requires a book and at least one target package")
+	}
+	pkgs, err := LoadPackages(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	content, err := EmitDockerfile(NewDependencyGraph(pkgs, recommended), flags.Args()[1:], opts)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Printf("%s", content)
+		return nil
+	}
+	return ioutil.WriteFile(output, content, 0644)
+}
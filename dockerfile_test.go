@@ -0,0 +1,85 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestEmitDockerfile func takes no input and returns t *testing.T
+func TestEmitDockerfile(t *testing.T) {
+	tcl := &PackageInformation{
+		Name:         "tcl",
+		Version:      "8.6.9",
+		Dependencies: Dependencies{Requires: []string{"zlib-1.2.11"}},
+		Sources: []Source{
+			{Archive: "https://downloads.sourceforge.net/tcl/tcl8.6.9-src.tar.gz", MD5Sum: "aa0a121d95a0e7b73a036f26028538d4"},
+			{Archive: "https://downloads.sourceforge.net/tcl/tcl8.6.9-html.tar.gz", MD5Sum: "243da67cca49b9bac0dc6c06fdb42896"},
+		},
+		Commands: []Command{
+			{Cmd: "tar -xf ../tcl8.6.9-html.tar.gz --strip-components=1", Index: 0},
+			{Cmd: "cd unix &&\n./configure --prefix=/usr &&\nmake", Index: 1},
+			{Cmd: "make install", Index: 2, Root: true, Section: "installation"},
+		},
+	}
+	zlib := &PackageInformation{
+		Name:    "zlib",
+		Version: "1.2.11",
+		Sources: []Source{{Archive: "https://zlib.net/zlib-1.2.11.tar.xz", MD5Sum: "85adef240c5f370b308da8c938951a68"}},
+		Commands: []Command{
+			{Cmd: "./configure --prefix=/usr &&\nmake", Index: 0, Section: "installation"},
+			{Cmd: "make install", Index: 1, Root: true, Section: "installation"},
+		},
+	}
+	graph := NewDependencyGraph([]*PackageInformation{tcl, zlib}, false)
+	content, err := EmitDockerfile(graph, []string{"tcl"}, DockerfileOptions{Base: "debian:bookworm", User: "builder"})
+	assert.Assert(t, is.Nil(err))
+	dockerfile := string(content)
+	fmt.Printf("%s\n", dockerfile)
+	assert.Assert(t, strings.Contains(dockerfile, "FROM debian:bookworm AS cmdext-base\n"))
+	assert.Assert(t, strings.Index(dockerfile, "AS zlib\n") < strings.Index(dockerfile, "AS tcl\n"))
+	assert.Assert(t, strings.Contains(dockerfile, "FROM cmdext-base AS tcl\nCOPY --from=zlib /pkg/zlib/ /\nUSER builder\nRUN mkdir -p /pkg/tcl\nWORKDIR /build/tcl\n"))
+	assert.Assert(t, strings.Contains(dockerfile, "ADD --chown=builder https://zlib.net/zlib-1.2.11.tar.xz zlib-1.2.11.tar.xz\n"))
+	assert.Assert(t, strings.Contains(dockerfile, "RUN md5sum -c - <<'CMDEXT'\n85adef240c5f370b308da8c938951a68  zlib-1.2.11.tar.xz\nCMDEXT\n"))
+	assert.Assert(t, strings.Contains(dockerfile, "RUN tar -xf zlib-1.2.11.tar.xz\n"))
+	zlibDir := `/build/zlib/$(tar -tf "/build/zlib/zlib-1.2.11.tar.xz" | head -n 1 | sed -e 's@^\./@@' -e 's@/.*@@')`
+	assert.Assert(t, strings.Contains(dockerfile, "USER root\n# installation\nRUN bash -e <<'CMDEXT'\ncd \""+zlibDir+"\"\nmake DESTDIR=/pkg/zlib install\nCMDEXT\n"))
+	tclDir := `/build/tcl/$(tar -tf "/build/tcl/tcl8.6.9-src.tar.gz" | head -n 1 | sed -e 's@^\./@@' -e 's@/.*@@')`
+	assert.Assert(t, strings.Contains(dockerfile, "# commands\nRUN bash -e <<'CMDEXT'\ncd \""+tclDir+"\"\ntar -xf ../tcl8.6.9-html.tar.gz --strip-components=1\n"))
+	assert.Assert(t, strings.Contains(dockerfile, "# installation\nRUN bash -e <<'CMDEXT'\ncd \""+tclDir+"/unix\"\nmake DESTDIR=/pkg/tcl install\n"))
+
+	content, err = EmitDockerfile(graph, []string{"tcl"}, DockerfileOptions{Base: "debian:bookworm", User: "builder", EmitOptions: EmitOptions{Destdir: "/stage/"}})
+	assert.Assert(t, is.Nil(err))
+	dockerfile = string(content)
+	assert.Assert(t, strings.Contains(dockerfile, "install -d -o builder /build /stage\n"))
+	assert.Assert(t, strings.Contains(dockerfile, "COPY --from=zlib /stage/zlib/ /\nUSER builder\nRUN mkdir -p /stage/tcl\n"))
+	assert.Assert(t, strings.Contains(dockerfile, "make DESTDIR=/stage/zlib install\n"))
+
+	_, err = EmitDockerfile(graph, []string{"unknown"}, DockerfileOptions{})
+	assert.ErrorContains(t, err, "no known packages")
+}
+
+// TestDockerBlocks func takes no input and returns t *testing.T
+func TestDockerBlocks(t *testing.T) {
+	blocks := dockerBlocks([]Command{
+		{Cmd: "cd build &&\nmake", Index: 0, Section: "installation"},
+		{Cmd: "make check", Index: 1, Section: "installation"},
+		{Cmd: "make install", Index: 2, Section: "installation", Root: true},
+		{Cmd: "install -m644 foo.conf /etc", Index: 3, Section: "configuration", Root: true},
+		{Cmd: "foo --init", Index: 4, Section: "configuration"},
+	}, "/pkg/foo", "builder", "/build/foo")
+	assert.Equal(t, len(blocks), 4)
+	assert.Equal(t, blocks[0].Script, "cd \"/build/foo\"\ncd build &&\nmake\nmake check")
+	assert.Equal(t, blocks[1].Script, "cd \"/build/foo/build\"\nmake DESTDIR=/pkg/foo install")
+	assert.Equal(t, blocks[0].User, "")
+	assert.Equal(t, blocks[1].User, "root")
+	assert.Equal(t, blocks[2].User, "")
+	assert.Equal(t, blocks[3].User, "builder")
+	assert.Equal(t, stageName("GTK+-3"), "gtk-3")
+}
@@ -0,0 +1,304 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmitOptions struct for emitoptions
+// SourceCache is a directory holding downloaded source archives and Destdir
+// replaces the directory an emitter stages install commands into. Date is
+// written where a build file records when it was made, so the same input
+// emits the same files.
+type EmitOptions struct {
+	SourceCache string
+	Destdir     string
+	Date        time.Time
+}
+
+// sourceDateLayout is the layout of dates given on the command line
+const sourceDateLayout = "2006-01-02"
+
+// SourceDate func takes date, file string input and returns time.Time, error
+// The date build files are emitted with. An explicit date is used first,
+// then SOURCE_DATE_EPOCH, then the modification time of the input file.
+func SourceDate(date, file string) (time.Time, error) {
+	if date != "" {
+		t, err := time.Parse(sourceDateLayout, date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse date %s : %v", date, err)
+		}
+		return t, nil
+	}
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		secs, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse SOURCE_DATE_EPOCH %s : %v", epoch, err)
+		}
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s : %v", file, err)
+	}
+	return info.ModTime().UTC(), nil
+}
+
+// StagingDir func takes def string input and returns string
+// The staging directory given in the options or the emitter's default.
+func (o EmitOptions) StagingDir(def string) string {
+	if o.Destdir != "" {
+		return o.Destdir
+	}
+	return def
+}
+
+// stagedStages func takes cmds []Command, destdir string input and returns map[string][]Command
+// Commands are classified into build stages and the install stage and root
+// commands are staged into destdir.
+func stagedStages(cmds []Command, destdir string) map[string][]Command {
+	stages := make(map[string][]Command, len(Stages))
+	staged := StageCommands(cmds, destdir)
+	for i, stage := range commandStages(cmds) {
+		stages[stage] = append(stages[stage], staged[i])
+	}
+	return stages
+}
+
+// EmittedFile struct for emittedfile
+// Path is relative to the destination directory.
+type EmittedFile struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+}
+
+// Emitter struct for emitter
+type Emitter struct {
+	Name string
+	Emit func(pkgInfo *PackageInformation, opts EmitOptions) ([]EmittedFile, error)
+}
+
+// singleFile func takes filename, emit func input and returns func
+// singleFile adapts an emitter of one file to the Emitter interface.
+func singleFile(filename func(pkgInfo *PackageInformation) string,
+	emit func(pkgInfo *PackageInformation, opts EmitOptions) ([]byte, error)) func(*PackageInformation, EmitOptions) ([]EmittedFile, error) {
+	return func(pkgInfo *PackageInformation, opts EmitOptions) ([]EmittedFile, error) {
+		content, err := emit(pkgInfo, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []EmittedFile{{Path: filename(pkgInfo), Content: content, Mode: 0644}}, nil
+	}
+}
+
+// WriteEmittedFiles func takes destdir string, files []EmittedFile input and returns error
+func WriteEmittedFiles(destdir string, files []EmittedFile) error {
+	for _, file := range files {
+		filepath := path.Join(destdir, file.Path)
+		if err := os.MkdirAll(path.Dir(filepath), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath, file.Content, file.Mode); err != nil {
+			return fmt.Errorf("failed to write %s : %v", filepath, err)
+		}
+	}
+	return nil
+}
+
+// emitters holds the package file emitters by name
+var emitters = map[string]Emitter{}
+
+// RegisterEmitter func takes e Emitter input and returns
+func RegisterEmitter(e Emitter) {
+	emitters[e.Name] = e
+}
+
+// EmitterNames func takes no input and returns []string
+func EmitterNames() []string {
+	names := make([]string, 0, len(emitters))
+	for name := range emitters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetEmitter func takes name string input and returns Emitter, error
+func GetEmitter(name string) (Emitter, error) {
+	e, ok := emitters[name]
+	if !ok {
+		return e, fmt.Errorf("unknown emitter %q, expected one of %s", name, strings.Join(EmitterNames(), ", "))
+	}
+	return e, nil
+}
+
+// sourceFile func takes archive string input and returns string
+func sourceFile(archive string) string {
+	return path.Base(archive)
+}
+
+// topDirCommand is the shell pipeline printing the top directory in the
+// listing of the archive %s
+const topDirCommand = `tar -tf %s | head -n 1 | sed -e 's@^\./@@' -e 's@/.*@@'`
+
+// unpackedArchive func takes pkgInfo *PackageInformation input and returns string
+// The file name of the primary source when it is an archive to unpack.
+func unpackedArchive(pkgInfo *PackageInformation) string {
+	primary := primarySource(pkgInfo)
+	if primary.Archive == "" || isPatch(primary.Archive) {
+		return ""
+	}
+	return sourceFile(primary.Archive)
+}
+
+// sourceDirCommand func takes pkgInfo *PackageInformation, archive string input and returns string
+// A shell command printing the directory the primary source unpacks to.
+// archive is the shell text of the unpacked archive's path, empty when there
+// is none. The package's SourceDir is used when set, otherwise the directory
+// is read from the archive listing at build time.
+func sourceDirCommand(pkgInfo *PackageInformation, archive string) string {
+	switch {
+	case pkgInfo.SourceDir != "":
+		return "echo " + shellQuote(pkgInfo.SourceDir)
+	case archive == "":
+		return "echo ."
+	}
+	return fmt.Sprintf(topDirCommand, archive)
+}
+
+// sourceDirWord func takes pkgInfo *PackageInformation, archive string input and returns string
+// The directory of sourceDirCommand as shell text for use within double quotes.
+func sourceDirWord(pkgInfo *PackageInformation, archive string) string {
+	switch {
+	case pkgInfo.SourceDir != "":
+		return doubleQuote(pkgInfo.SourceDir)
+	case archive == "":
+		return "."
+	}
+	return "$(" + fmt.Sprintf(topDirCommand, archive) + ")"
+}
+
+// commandDir struct for commanddir
+// Start and End are the directories, relative to the unpacked source, a
+// command starts in and leaves the shell in.
+type commandDir struct {
+	Start string
+	End   string
+}
+
+// commandDirs func takes cmds []Command input and returns map[int]commandDir
+// The commands are followed in Index order as if run in one shell from the
+// unpacked source. A command that fails to parse leaves the directory as is.
+func commandDirs(cmds []Command) map[int]commandDir {
+	dirs := make(map[int]commandDir, len(cmds))
+	dir := "."
+	for _, cmd := range sortedCommands(cmds) {
+		_, end, err := ParseSteps(cmd, dir)
+		if err != nil {
+			end = dir
+		}
+		dirs[cmd.Index] = commandDir{Start: dir, End: end}
+		dir = end
+	}
+	return dirs
+}
+
+// cdCommand func takes base, dir string input and returns string
+// base is the unpacked source as shell text for use within double quotes.
+func cdCommand(base, dir string) string {
+	switch {
+	case dir == ".":
+		return `cd "` + base + `"`
+	case dir == "~" || strings.HasPrefix(dir, "~/"):
+		return "cd " + dir
+	case path.IsAbs(dir) || strings.HasPrefix(dir, "$"):
+		return `cd "` + dir + `"`
+	}
+	return `cd "` + base + "/" + dir + `"`
+}
+
+// stageTexts func takes cmds []Command, dirs map[int]commandDir, base, dir string input and returns []string, string
+// The staged texts of commands run in one shell starting in dir. Where the
+// shell is not in the directory a command starts in, following the commands
+// before it in book order, a cd to it is added. The directory the shell is
+// left in is returned.
+func stageTexts(cmds []Command, dirs map[int]commandDir, base, dir string) ([]string, string) {
+	texts := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		if d, ok := dirs[cmd.Index]; ok {
+			if d.Start != dir {
+				texts = append(texts, cdCommand(base, d.Start))
+			}
+			dir = d.End
+		}
+		texts = append(texts, stagedText(cmd))
+	}
+	return texts, dir
+}
+
+// cachedChecksum func takes cache, archive string, h hash.Hash input and returns string
+// The hex checksum of the archive in the cache directory, or "" when it is not cached.
+func cachedChecksum(cache, archive string, h hash.Hash) string {
+	if cache == "" {
+		return ""
+	}
+	f, err := os.Open(path.Join(cache, sourceFile(archive)))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isPatch func takes archive string input and returns bool
+func isPatch(archive string) bool {
+	return strings.HasSuffix(archive, ".patch") || strings.HasSuffix(archive, ".diff")
+}
+
+// primarySource func takes pkgInfo *PackageInformation input and returns Source
+func primarySource(pkgInfo *PackageInformation) Source {
+	for _, src := range pkgInfo.Sources {
+		if src.Archive != "" {
+			return src
+		}
+	}
+	return Source{}
+}
+
+// emitterSources func takes pkgInfo *PackageInformation input and returns []Source
+// Sources without a download location are left out.
+func emitterSources(pkgInfo *PackageInformation) []Source {
+	srcs := make([]Source, 0, len(pkgInfo.Sources))
+	for _, src := range pkgInfo.Sources {
+		if src.Archive != "" {
+			srcs = append(srcs, src)
+		}
+	}
+	return srcs
+}
+
+// summary func takes description string input and returns string
+// The first sentence of the description, on a single line.
+func summary(description string) string {
+	description = strings.Join(strings.Fields(description), " ")
+	if i := strings.Index(description, ". "); i > 0 {
+		description = description[:i]
+	}
+	return strings.TrimSuffix(description, ".")
+}
@@ -0,0 +1,130 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// tclPackage func takes opts ...func(*PackageInformation) input and returns *PackageInformation
+// The tcl package the emitter tests share. Its build changes to the unix
+// directory the check and install stages run from, and opts such as
+// withPatch and withContents add to it.
+func tclPackage(opts ...func(*PackageInformation)) *PackageInformation {
+	pkgInfo := &PackageInformation{
+		Name:        "tcl",
+		Version:     "8.6.9",
+		Description: "The Tcl package contains the Tool Command Language, a robust general-purpose scripting language.",
+		Commands: []Command{
+			{Cmd: "tar -xf ../tcl8.6.9-html.tar.gz --strip-components=1", Index: 0},
+			{Cmd: "export SRCDIR=`pwd` &&\n\ncd unix &&\n\n./configure --prefix=/usr \\\n            --mandir=/usr/share/man &&\nmake", Index: 1},
+			{Cmd: "make test", Index: 2},
+			{Cmd: "make install &&\nmake install-private-headers &&\nln -v -sf tclsh8.6 /usr/bin/tclsh", Index: 3, Root: true},
+		},
+		Dependencies: Dependencies{
+			Requires:    []string{"zlib-1.2.11"},
+			Recommended: []string{"tk-8.6.9"},
+			Optional:    []string{"doxygen-1.8.16"},
+		},
+		Sources: []Source{
+			{Archive: "https://downloads.sourceforge.net/tcl/tcl8.6.9-src.tar.gz", MD5Sum: "aa0a121d95a0e7b73a036f26028538d4"},
+			{Archive: "https://downloads.sourceforge.net/tcl/tcl8.6.9-html.tar.gz", MD5Sum: "243da67cca49b9bac0dc6c06fdb42896"},
+		},
+	}
+	for _, opt := range opts {
+		opt(pkgInfo)
+	}
+	return pkgInfo
+}
+
+// withPatch func takes pkgInfo *PackageInformation input
+// A patch applied by a first command and listed as a source.
+func withPatch(pkgInfo *PackageInformation) {
+	cmds := append([]Command{{Cmd: "patch -Np1 -i ../tcl-8.6.9-fix-1.patch"}}, pkgInfo.Commands...)
+	for i := range cmds {
+		cmds[i].Index = i
+	}
+	pkgInfo.Commands = cmds
+	pkgInfo.Sources = append(pkgInfo.Sources, Source{Archive: "http://www.linuxfromscratch.org/patches/blfs/9.0/tcl-8.6.9-fix-1.patch"})
+}
+
+// withContents func takes pkgInfo *PackageInformation input
+// The installed programs, libraries and directories.
+func withContents(pkgInfo *PackageInformation) {
+	pkgInfo.Contents = &Contents{
+		Programs:    []string{"tclsh", "tclsh8.6"},
+		Libraries:   []string{"libtcl8.6.so", "libtclstub8.6.a"},
+		Directories: []string{"/usr/lib/tcl8", "/usr/share/doc/tcl-8.6.9"},
+	}
+}
+
+// TestSourceDirWord func takes no input and returns t *testing.T
+func TestSourceDirWord(t *testing.T) {
+	pkgInfo := &PackageInformation{Name: "tcl"}
+	assert.Equal(t, sourceDirWord(pkgInfo, ""), ".")
+	assert.Equal(t, sourceDirCommand(pkgInfo, ""), "echo .")
+	assert.Equal(t, sourceDirWord(pkgInfo, `"tcl8.6.9-src.tar.gz"`), `$(tar -tf "tcl8.6.9-src.tar.gz" | head -n 1 | sed -e 's@^\./@@' -e 's@/.*@@')`)
+	pkgInfo.SourceDir = "tcl$8"
+	assert.Equal(t, sourceDirWord(pkgInfo, `"tcl8.6.9-src.tar.gz"`), `tcl\$8`)
+	assert.Equal(t, sourceDirCommand(pkgInfo, `"tcl8.6.9-src.tar.gz"`), "echo 'tcl$8'")
+}
+
+// TestStageTexts func takes no input and returns t *testing.T
+func TestStageTexts(t *testing.T) {
+	cmds := []Command{
+		{Cmd: "mkdir build &&\ncd build &&\ncmake ..", Index: 0},
+		{Cmd: "make", Index: 1},
+		{Cmd: "make test", Index: 2},
+		{Cmd: "cd /tmp &&\nmake install", Index: 3, Root: true},
+		{Cmd: "make -C ~/doc", Index: 4},
+	}
+	dirs := commandDirs(cmds)
+	assert.Equal(t, dirs[0], commandDir{Start: ".", End: "build"})
+	assert.Equal(t, dirs[2], commandDir{Start: "build", End: "build"})
+	assert.Equal(t, dirs[4], commandDir{Start: "/tmp", End: "/tmp"})
+
+	texts, dir := stageTexts(cmds[:2], dirs, "$srcdir", ".")
+	assert.DeepEqual(t, texts, []string{cmds[0].Cmd, "make"})
+	assert.Equal(t, dir, "build")
+	texts, dir = stageTexts(cmds[2:], dirs, "$srcdir", ".")
+	assert.DeepEqual(t, texts, []string{`cd "$srcdir/build"`, "make test", cmds[3].Cmd, "make -C ~/doc"})
+	assert.Equal(t, dir, "/tmp")
+
+	assert.Equal(t, cdCommand("$srcdir", "."), `cd "$srcdir"`)
+	assert.Equal(t, cdCommand("$srcdir", "~/src"), "cd ~/src")
+	assert.Equal(t, cdCommand("$srcdir", "$SRCDIR/unix"), `cd "$SRCDIR/unix"`)
+}
+
+// TestSourceDate func takes no input and returns t *testing.T
+func TestSourceDate(t *testing.T) {
+	date, err := SourceDate("2019-09-01", "")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, date, time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC))
+	_, err = SourceDate("September", "")
+	assert.ErrorContains(t, err, "failed to parse date")
+
+	defer os.Setenv("SOURCE_DATE_EPOCH", os.Getenv("SOURCE_DATE_EPOCH"))
+	os.Setenv("SOURCE_DATE_EPOCH", "1567339200")
+	date, err = SourceDate("", "")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, date, time.Date(2019, 9, 1, 12, 0, 0, 0, time.UTC))
+
+	os.Unsetenv("SOURCE_DATE_EPOCH")
+	f, err := ioutil.TempFile("", "page")
+	assert.Assert(t, is.Nil(err))
+	f.Close()
+	defer os.Remove(f.Name())
+	mtime := time.Date(2019, 9, 2, 0, 0, 0, 0, time.UTC)
+	err = os.Chtimes(f.Name(), mtime, mtime)
+	assert.Assert(t, is.Nil(err))
+	date, err = SourceDate("", f.Name())
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, date.Equal(mtime))
+}
@@ -0,0 +1,137 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Explanation struct for explanation
+// A term of the Command Explanations section and the text describing it.
+// Commands are the indexes of the Commands the term is found in.
+type Explanation struct {
+	Term     string `json:"term" yaml:"term"`
+	Text     string `json:"text" yaml:"text"`
+	Commands []int  `json:"commands,omitempty" yaml:"commands,omitempty"`
+}
+
+// explanationTerms selects the elements a Command Explanations paragraph names its terms with
+const explanationTerms = "em.parameter code, code.option, code.envar, span.command strong"
+
+// collapse func takes s string input and returns string
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// termWords func takes term string input and returns []string
+// The words of a term as the shell splits them. Terms that do not parse as
+// a single simple command, e.g. --with-foo=<dir>, are split on spaces.
+func termWords(term string) []string {
+	steps, _, err := ParseSteps(Command{Cmd: term}, ".")
+	if err != nil || len(steps) != 1 || len(steps[0].Argv) == 0 {
+		return strings.Fields(term)
+	}
+	return steps[0].Argv
+}
+
+// containsWords func takes argv, words []string input and returns bool
+// words is found as consecutive arguments of argv.
+func containsWords(argv, words []string) bool {
+	for i := 0; i+len(words) <= len(argv); i++ {
+		match := true
+		for j, word := range words {
+			if argv[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// commandArgvs func takes cmds []Command input and returns map[int][][]string
+// The argv of each step of the commands by command index.
+func commandArgvs(cmds []Command) map[int][][]string {
+	argvs := make(map[int][][]string, len(cmds))
+	for _, cmd := range cmds {
+		steps, _, err := ParseSteps(cmd, ".")
+		if err != nil {
+			continue
+		}
+		for _, step := range steps {
+			argvs[cmd.Index] = append(argvs[cmd.Index], step.Argv)
+		}
+	}
+	return argvs
+}
+
+// explainedCommands func takes term string, cmds []Command, argvs map[int][][]string input and returns []int
+// A command is explained by a term when one of its steps has the term's
+// words as arguments. A switch written with a placeholder value, e.g.
+// --with-foo=<dir>, matches the switch with any value when the whole term
+// is not found.
+func explainedCommands(term string, cmds []Command, argvs map[int][][]string) []int {
+	find := func(match func(argv []string) bool) []int {
+		var found []int
+		for _, cmd := range cmds {
+			for _, argv := range argvs[cmd.Index] {
+				if match(argv) {
+					found = append(found, cmd.Index)
+					break
+				}
+			}
+		}
+		return found
+	}
+	words := termWords(term)
+	found := find(func(argv []string) bool { return containsWords(argv, words) })
+	if flag, _ := splitOption(term); len(found) == 0 && flag != term && strings.HasPrefix(flag, "-") && len(words) == 1 {
+		found = find(func(argv []string) bool {
+			for _, arg := range argv {
+				if f, _ := splitOption(arg); f == flag && f != arg {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	return found
+}
+
+// ExtractExplanations func takes doc *goquery.Document, cmds []Command input and returns []Explanation
+// Paragraphs of the Command Explanations section start with the terms they
+// explain followed by a colon, e.g.
+// <em class="parameter"><code>--disable-static</code></em>: text. A
+// paragraph naming several terms gives an Explanation for each of them.
+func ExtractExplanations(doc *goquery.Document, cmds []Command) []Explanation {
+	explanations := make([]Explanation, 0)
+	argvs := commandArgvs(cmds)
+	doc.Find(".commands p").Each(func(i int, p *goquery.Selection) {
+		text := collapse(p.Text())
+		colon := strings.Index(text, ": ")
+		if colon < 0 {
+			return
+		}
+		lead := text[:colon]
+		seen := make(map[string]bool)
+		p.Find(explanationTerms).Each(func(i int, s *goquery.Selection) {
+			term := collapse(s.Text())
+			if term == "" || seen[term] || !strings.Contains(lead, term) {
+				return
+			}
+			seen[term] = true
+			explanations = append(explanations, Explanation{
+				Term:     term,
+				Text:     text[colon+2:],
+				Commands: explainedCommands(term, cmds, argvs),
+			})
+		})
+	})
+	return explanations
+}
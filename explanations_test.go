@@ -0,0 +1,76 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// explanationsPage is a page with a Command Explanations section
+const explanationsPage = `<html><body>
+<div class="installation">
+<pre class="userinput"><kbd class="command">sed -i '/install-data-local/d' Makefile.in &amp;&amp;
+./configure --prefix=/usr    \
+            --disable-static \
+            --enable-gtk-doc \
+            --with-dbus-sys=/etc/dbus-1/system.d &amp;&amp;
+make</kbd></pre>
+<pre class="root"><kbd class="command">make install</kbd></pre>
+</div>
+<div class="commands">
+<h2 class="sect2">Command Explanations</h2>
+<p><span class="command"><strong>sed -i '/install-data-local/d'
+Makefile.in</strong></span>: This sed keeps the <em>examples</em> from
+being installed.</p>
+<p><em class="parameter"><code>--disable-static</code></em>: This switch
+prevents installation of static versions of the libraries.</p>
+<p><em class="parameter"><code>--enable-gtk-doc</code></em> and
+<code class="option">--enable-man</code>: These switches build the
+documentation.</p>
+<p><em class="parameter"><code>--with-dbus-sys=&lt;dir&gt;</code></em>: This
+switch sets where the D-Bus policy is installed.</p>
+<p><em>Also</em> <code class="option">--enable-gtk</code>: This switch
+builds the GTK+ frontend.</p>
+<p>The test suite needs a running X server.</p>
+</div>
+</body></html>`
+
+// TestExtractExplanations func takes no input and returns t *testing.T
+func TestExtractExplanations(t *testing.T) {
+	doc, err := ReadDoc([]byte(explanationsPage))
+	assert.Assert(t, is.Nil(err))
+	cmds, err := ExtractCommands(doc)
+	assert.Assert(t, is.Nil(err))
+	explanations := ExtractExplanations(doc, cmds)
+	assert.DeepEqual(t, explanations, []Explanation{
+		{Term: "sed -i '/install-data-local/d' Makefile.in", Text: "This sed keeps the examples from being installed.", Commands: []int{0}},
+		{Term: "--disable-static", Text: "This switch prevents installation of static versions of the libraries.", Commands: []int{0}},
+		{Term: "--enable-gtk-doc", Text: "These switches build the documentation.", Commands: []int{0}},
+		{Term: "--enable-man", Text: "These switches build the documentation."},
+		{Term: "--with-dbus-sys=<dir>", Text: "This switch sets where the D-Bus policy is installed.", Commands: []int{0}},
+		{Term: "--enable-gtk", Text: "This switch builds the GTK+ frontend."},
+	})
+
+	options := ExtractOptions(cmds, explanations)
+	assert.Equal(t, options[3].Flag, "--with-dbus-sys")
+	assert.Equal(t, options[3].Explanation, "This switch sets where the D-Bus policy is installed.")
+}
+
+// TestExplainedCommands func takes no input and returns t *testing.T
+func TestExplainedCommands(t *testing.T) {
+	cmds := []Command{
+		{Cmd: "./configure --prefix=/usr --enable-gtk-doc &&\nmake", Index: 0},
+		{Cmd: "make check", Index: 1},
+		{Cmd: "sed -i 's/check/verify/' Makefile", Index: 2},
+	}
+	argvs := commandArgvs(cmds)
+	assert.Assert(t, is.Len(explainedCommands("--enable-gtk", cmds, argvs), 0))
+	assert.DeepEqual(t, explainedCommands("--enable-gtk-doc", cmds, argvs), []int{0})
+	assert.DeepEqual(t, explainedCommands("make check", cmds, argvs), []int{1})
+	assert.DeepEqual(t, explainedCommands("--prefix=<dir>", cmds, argvs), []int{0})
+	assert.DeepEqual(t, explainedCommands("sed -i 's/check/verify/' Makefile", cmds, argvs), []int{2})
+}
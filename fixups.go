@@ -0,0 +1,218 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SourceFixup struct for sourcefixup
+// A sed -i edit or patch applied to the unpacked source before it is
+// configured. Files are the files a sed edits and Patch the patch file
+// applied, with Source the archive of the matching download and Strip its -p
+// level. Command and Line place the step in the commands, Dir is the
+// directory it runs in.
+type SourceFixup struct {
+	Kind    string   `json:"kind" yaml:"kind"`
+	Command int      `json:"command" yaml:"command"`
+	Line    int      `json:"line" yaml:"line"`
+	Dir     string   `json:"dir" yaml:"dir"`
+	Argv    []string `json:"argv" yaml:"argv"`
+	Files   []string `json:"files,omitempty" yaml:"files,omitempty"`
+	Patch   string   `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Source  string   `json:"source,omitempty" yaml:"source,omitempty"`
+	Strip   int      `json:"strip,omitempty" yaml:"strip,omitempty"`
+}
+
+// buildSteps are the programs that end the fixups of a package
+var buildSteps = map[string]bool{"make": true, "ninja": true, "autoreconf": true, "python3": true, "pip3": true}
+
+// sedValued are the sed options taking their value from the next argument
+var sedValued = map[string]bool{"-e": true, "-f": true, "-l": true, "--expression": true, "--file": true}
+
+// sedFixup func takes step Step input and returns SourceFixup, bool
+// An in place sed of files in the source tree. Scripts given with -e or -f
+// are skipped, otherwise the first operand is the script.
+func sedFixup(step Step) (SourceFixup, bool) {
+	fixup := SourceFixup{Kind: "sed"}
+	inPlace, script := false, false
+	args := step.Argv[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		flag, _ := splitOption(arg)
+		switch {
+		case arg == "--":
+			continue
+		case sedValued[flag]:
+			script = script || flag != "-l"
+			if flag == arg && i+1 < len(args) {
+				i++
+			}
+		case strings.HasPrefix(arg, "--in-place"), strings.HasPrefix(arg, "-i"):
+			inPlace = true
+		case strings.HasPrefix(arg, "--"):
+			continue
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			inPlace = inPlace || strings.Contains(arg, "i")
+		case !script:
+			script = true
+		default:
+			fixup.Files = append(fixup.Files, arg)
+		}
+	}
+	if !inPlace || len(fixup.Files) == 0 {
+		return fixup, false
+	}
+	for _, file := range fixup.Files {
+		if !isSourcePath(file) {
+			return fixup, false
+		}
+	}
+	return fixup, true
+}
+
+// patchFixup func takes step Step input and returns SourceFixup, bool
+// The patch file is given with -i, --input or a < redirect.
+func patchFixup(step Step) (SourceFixup, bool) {
+	fixup := SourceFixup{Kind: "patch"}
+	args := step.Argv[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		flag, value := splitOption(arg)
+		switch {
+		case arg == "-i" || arg == "--input":
+			if i+1 < len(args) {
+				i++
+				fixup.Patch = args[i]
+			}
+		case flag == "--input":
+			fixup.Patch = value
+		case strings.HasPrefix(arg, "--strip="):
+			fixup.Strip, _ = strconv.Atoi(value)
+		case strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--"):
+			opts := arg[1:]
+			if p := strings.Index(opts, "p"); p >= 0 {
+				fixup.Strip, _ = strconv.Atoi(strings.TrimLeft(opts[p+1:], "p"))
+				opts = opts[:p]
+			}
+			if strings.HasSuffix(opts, "i") && i+1 < len(args) {
+				i++
+				fixup.Patch = args[i]
+			}
+		}
+	}
+	for _, r := range step.Redirects {
+		if r.Op == "<" {
+			fixup.Patch = r.Target
+		}
+	}
+	return fixup, fixup.Patch != ""
+}
+
+// isSourcePath func takes p string input and returns bool
+// Paths in the source tree, not absolute or made from variables.
+func isSourcePath(p string) bool {
+	return !strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "$") && !strings.HasPrefix(p, "~")
+}
+
+// patchSource func takes patch string, sources []Source input and returns string
+// The archive of the download the patch file is, matched by file name.
+func patchSource(patch string, sources []Source) string {
+	for _, src := range sources {
+		if src.Archive != "" && sourceFile(src.Archive) == sourceFile(patch) {
+			return src.Archive
+		}
+	}
+	return ""
+}
+
+// patchLabels start the download entries of the patches a page lists
+var patchLabels = []string{"Required patch:", "Recommended patch:", "Optional patch:"}
+
+// ExtractPatches func takes doc *goquery.Document input and returns []Source
+// Every patch the page lists, required, recommended or optional. Only the
+// required ones are Sources of the package.
+func ExtractPatches(doc *goquery.Document) []Source {
+	patches := make([]Source, 0)
+	doc.Find(".package .itemizedlist .compact p").Each(func(i int, s *goquery.Selection) {
+		block := s.Text()
+		for _, label := range patchLabels {
+			if strings.Contains(block, label) {
+				patches = append(patches, Source{Archive: strings.TrimSpace(s.Find(".ulink").Text())})
+				break
+			}
+		}
+	})
+	return patches
+}
+
+// resolvePatches func takes pkgInfo *PackageInformation, patches []Source input and returns []string
+// Patch fixups without a source are looked up in patches, the full patch
+// list of the page, and the patches found there are added to Sources since
+// the commands apply them. The patches still without a source are returned.
+func resolvePatches(pkgInfo *PackageInformation, patches []Source) []string {
+	var missing []string
+	for i, fixup := range pkgInfo.Fixups {
+		if fixup.Kind != "patch" || fixup.Source != "" {
+			continue
+		}
+		if fixup.Source = patchSource(fixup.Patch, patches); fixup.Source == "" {
+			missing = append(missing, fixup.Patch)
+			continue
+		}
+		if patchSource(fixup.Patch, pkgInfo.Sources) == "" {
+			pkgInfo.Sources = append(pkgInfo.Sources, Source{Archive: fixup.Source})
+		}
+		pkgInfo.Fixups[i] = fixup
+	}
+	return missing
+}
+
+// ExtractFixups func takes pkgInfo *PackageInformation input and returns []SourceFixup
+// Fixups are the sed -i and patch steps run on the source before its first
+// configure or build step. Commands made only of fixups, and cd, are marked
+// as Fixup. Patches are resolved to the package's Sources.
+func ExtractFixups(pkgInfo *PackageInformation) []SourceFixup {
+	fixups := make([]SourceFixup, 0)
+	building := false
+	for i, cmd := range pkgInfo.Commands {
+		if building {
+			break
+		}
+		steps, _, err := ParseSteps(cmd, ".")
+		if err != nil {
+			continue
+		}
+		found, only := 0, true
+		for _, step := range steps {
+			if _, _, ok := stepTool(step); ok || buildSteps[step.Name()] {
+				building, only = true, false
+				break
+			}
+			var (
+				fixup SourceFixup
+				ok    bool
+			)
+			switch step.Name() {
+			case "sed":
+				fixup, ok = sedFixup(step)
+			case "patch":
+				fixup, ok = patchFixup(step)
+				fixup.Source = patchSource(fixup.Patch, pkgInfo.Sources)
+			}
+			if !ok {
+				only = only && step.Name() == "cd"
+				continue
+			}
+			fixup.Command, fixup.Line, fixup.Dir, fixup.Argv = cmd.Index, step.Line, step.Dir, step.Argv
+			fixups = append(fixups, fixup)
+			found++
+		}
+		pkgInfo.Commands[i].Fixup = only && found > 0
+	}
+	return fixups
+}
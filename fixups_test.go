@@ -0,0 +1,91 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// patchPage is a package block with a required and an optional patch
+const patchPage = `<html><body><div class="package">
+<div class="itemizedlist"><ul class="compact">
+<li><p>Download (HTTP): <a class="ulink" href="https://example.com/foo-1.0.tar.xz">https://example.com/foo-1.0.tar.xz</a></p></li>
+<li><p>Download MD5 sum: 0123456789abcdef</p></li>
+</ul></div>
+<h3>Additional Downloads</h3>
+<div class="itemizedlist"><ul class="compact">
+<li><p>Required patch: <a class="ulink" href="https://example.com/patches/foo-1.0-fixes-1.patch">https://example.com/patches/foo-1.0-fixes-1.patch</a></p></li>
+<li><p>Optional patch: <a class="ulink" href="https://example.com/patches/foo-1.0-extras-1.patch">https://example.com/patches/foo-1.0-extras-1.patch</a></p></li>
+</ul></div>
+</div></body></html>`
+
+// TestExtractFixups func takes no input and returns t *testing.T
+func TestExtractFixups(t *testing.T) {
+	doc, err := ReadDoc([]byte(patchPage))
+	assert.Assert(t, is.Nil(err))
+	sources, err := ExtractSources(doc)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, sources[1].Archive, "https://example.com/patches/foo-1.0-fixes-1.patch")
+	for _, src := range sources {
+		assert.Assert(t, src.Archive != "https://example.com/patches/foo-1.0-extras-1.patch")
+	}
+
+	pkgInfo := &PackageInformation{
+		Name:    "foo",
+		Sources: sources,
+		Commands: []Command{
+			{Cmd: "patch -Np1 -i ../foo-1.0-fixes-1.patch", Index: 0},
+			{Cmd: "sed -i '/^SUBDIRS/s/doc//' Makefile.in &&\ncd src &&\nsed -i.orig -e 's/foo/bar/' -e 's/a/b/' main.c config.h", Index: 1},
+			{Cmd: "sed -i 's/x/y/' /etc/foo.conf &&\npatch -p0 < ../unknown.diff &&\n./configure --prefix=/usr &&\nsed -i 's/-Werror//' Makefile &&\nmake", Index: 2},
+			{Cmd: "sed -i 's/y/z/' src/Makefile", Index: 3},
+		},
+	}
+	fixups := ExtractFixups(pkgInfo)
+	assert.DeepEqual(t, fixups, []SourceFixup{
+		{Kind: "patch", Command: 0, Line: 1, Dir: ".", Argv: []string{"patch", "-Np1", "-i", "../foo-1.0-fixes-1.patch"},
+			Patch: "../foo-1.0-fixes-1.patch", Source: "https://example.com/patches/foo-1.0-fixes-1.patch", Strip: 1},
+		{Kind: "sed", Command: 1, Line: 1, Dir: ".", Argv: []string{"sed", "-i", "/^SUBDIRS/s/doc//", "Makefile.in"},
+			Files: []string{"Makefile.in"}},
+		{Kind: "sed", Command: 1, Line: 3, Dir: "src", Argv: []string{"sed", "-i.orig", "-e", "s/foo/bar/", "-e", "s/a/b/", "main.c", "config.h"},
+			Files: []string{"main.c", "config.h"}},
+		{Kind: "patch", Command: 2, Line: 2, Dir: ".", Argv: []string{"patch", "-p0"}, Patch: "../unknown.diff"},
+	})
+	assert.Assert(t, pkgInfo.Commands[0].Fixup)
+	assert.Assert(t, pkgInfo.Commands[1].Fixup)
+	assert.Assert(t, !pkgInfo.Commands[2].Fixup)
+	assert.Assert(t, !pkgInfo.Commands[3].Fixup)
+}
+
+// TestResolvePatches func takes no input and returns t *testing.T
+func TestResolvePatches(t *testing.T) {
+	doc, err := ReadDoc([]byte(patchPage))
+	assert.Assert(t, is.Nil(err))
+	sources, err := ExtractSources(doc)
+	assert.Assert(t, is.Nil(err))
+	patches := ExtractPatches(doc)
+	assert.DeepEqual(t, patches, []Source{
+		{Archive: "https://example.com/patches/foo-1.0-fixes-1.patch"},
+		{Archive: "https://example.com/patches/foo-1.0-extras-1.patch"},
+	})
+
+	pkgInfo := &PackageInformation{
+		Name:    "foo",
+		Sources: sources,
+		Commands: []Command{
+			{Cmd: "patch -Np1 -i ../foo-1.0-fixes-1.patch &&\npatch -Np1 -i ../foo-1.0-extras-1.patch", Index: 0},
+			{Cmd: "patch -Np1 -i ../foo-1.0-missing-1.patch", Index: 1},
+		},
+	}
+	pkgInfo.Fixups = ExtractFixups(pkgInfo)
+	assert.Equal(t, pkgInfo.Fixups[1].Source, "")
+	missing := resolvePatches(pkgInfo, patches)
+	assert.DeepEqual(t, missing, []string{"../foo-1.0-missing-1.patch"})
+	assert.Equal(t, pkgInfo.Fixups[0].Source, "https://example.com/patches/foo-1.0-fixes-1.patch")
+	assert.Equal(t, pkgInfo.Fixups[1].Source, "https://example.com/patches/foo-1.0-extras-1.patch")
+	assert.Equal(t, len(pkgInfo.Sources), len(sources)+1)
+	assert.Equal(t, pkgInfo.Sources[len(sources)].Archive, "https://example.com/patches/foo-1.0-extras-1.patch")
+}
@@ -0,0 +1,139 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DependencyGraph struct for dependencygraph
+type DependencyGraph struct {
+	Packages    map[string]*PackageInformation
+	Recommended bool
+}
+
+// NewDependencyGraph func takes pkgs []*PackageInformation, recommended bool input and returns *DependencyGraph
+// Edges follow required dependencies, and recommended ones when recommended is set.
+func NewDependencyGraph(pkgs []*PackageInformation, recommended bool) *DependencyGraph {
+	return &DependencyGraph{
+		Packages:    PackageMap(pkgs),
+		Recommended: recommended,
+	}
+}
+
+// Edges func takes name string input and returns []string
+// Only dependencies that are known packages are returned, sorted by name.
+func (g *DependencyGraph) Edges(name string) []string {
+	pkg, ok := g.Packages[name]
+	if !ok {
+		return nil
+	}
+	deps := pkg.Dependencies.Requires
+	if g.Recommended {
+		deps = append(append([]string{}, deps...), pkg.Dependencies.Recommended...)
+	}
+	edges := make([]string, 0, len(deps))
+	seen := make(map[string]bool, len(deps))
+	for _, dep := range deps {
+		dep = g.Key(dep)
+		if dep == "" || seen[dep] || dep == name {
+			continue
+		}
+		seen[dep] = true
+		edges = append(edges, dep)
+	}
+	sort.Strings(edges)
+	return edges
+}
+
+// Key func takes dep string input and returns string
+// dep is a package key or a dependency such as "Python-3.7.4". A dependency
+// matches the key of its name and major version first, so it finds
+// "python-3" when the book holds two python packages, then its plain name and
+// finally the newest package of that name. Unknown dependencies return "".
+func (g *DependencyGraph) Key(dep string) string {
+	if _, ok := g.Packages[dep]; ok {
+		return dep
+	}
+	dep = strings.ToLower(strings.TrimSpace(dep))
+	if _, ok := g.Packages[dep]; ok {
+		return dep
+	}
+	name := DependencyName(dep)
+	if name != dep {
+		version := MustParseVersion(dep[len(name)+1:])
+		if len(version.Parts) > 0 {
+			key := fmt.Sprintf("%s-%d", name, version.Parts[0])
+			if _, ok := g.Packages[key]; ok {
+				return key
+			}
+		}
+	}
+	if _, ok := g.Packages[name]; ok {
+		return name
+	}
+	keys := make([]string, 0)
+	for key, pkg := range g.Packages {
+		if pkg.Name == name {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	newest := ""
+	for _, key := range keys {
+		if newest == "" || CompareVersions(g.Packages[key].Version, g.Packages[newest].Version) > 0 {
+			newest = key
+		}
+	}
+	return newest
+}
+
+// Order func takes targets []string input and returns []string
+// The returned list holds targets and everything they depend on, with
+// dependencies before the packages that need them. Dependency cycles are
+// broken at the first edge that closes them. Unknown targets are ignored and
+// an empty targets list orders every package in the graph.
+func (g *DependencyGraph) Order(targets []string) []string {
+	if len(targets) == 0 {
+		for name := range g.Packages {
+			targets = append(targets, name)
+		}
+		sort.Strings(targets)
+	}
+	order := make([]string, 0)
+	done := make(map[string]bool)
+	visiting := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if name = g.Key(name); name == "" {
+			return
+		}
+		if done[name] || visiting[name] {
+			return
+		}
+		visiting[name] = true
+		for _, dep := range g.Edges(name) {
+			visit(dep)
+		}
+		visiting[name] = false
+		done[name] = true
+		order = append(order, name)
+	}
+	for _, target := range targets {
+		visit(target)
+	}
+	return order
+}
+
+// OrderedPackages func takes targets []string input and returns []*PackageInformation
+// The packages of Order(targets), in the same order.
+func (g *DependencyGraph) OrderedPackages(targets []string) []*PackageInformation {
+	pkgs := make([]*PackageInformation, 0)
+	for _, name := range g.Order(targets) {
+		pkgs = append(pkgs, g.Packages[name])
+	}
+	return pkgs
+}
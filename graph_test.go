@@ -0,0 +1,30 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestDependencyGraphOrder func takes no input and returns t *testing.T
+func TestDependencyGraphOrder(t *testing.T) {
+	pkgs := []*PackageInformation{
+		{Name: "gtk", Dependencies: Dependencies{Requires: []string{"glib-2.62.0", "cairo-1.16.0"}}},
+		{Name: "cairo", Dependencies: Dependencies{Requires: []string{"glib-2.62.0"}, Recommended: []string{"pixman-0.38.4"}}},
+		{Name: "glib", Dependencies: Dependencies{Optional: []string{"gtk-3.24.10"}}},
+		{Name: "pixman"},
+		{Name: "freetype", Dependencies: Dependencies{Recommended: []string{"harfbuzz-2.6.1"}}},
+		{Name: "harfbuzz", Dependencies: Dependencies{Recommended: []string{"freetype-2.10.1"}}},
+	}
+	graph := NewDependencyGraph(pkgs, false)
+	assert.DeepEqual(t, graph.Order([]string{"gtk-3.24.10"}), []string{"glib", "cairo", "gtk"})
+	assert.DeepEqual(t, graph.Order([]string{"unknown"}), []string{})
+
+	graph = NewDependencyGraph(pkgs, true)
+	assert.DeepEqual(t, graph.Order([]string{"gtk"}), []string{"glib", "pixman", "cairo", "gtk"})
+	assert.DeepEqual(t, graph.Order([]string{"freetype"}), []string{"harfbuzz", "freetype"})
+	assert.Equal(t, len(graph.Order(nil)), len(pkgs))
+}
@@ -0,0 +1,363 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// jhalfsTemplate is the layout of a jhalfs style package script
+const jhalfsTemplate = `#!/bin/bash
+# Generated by cmdext from {{ .Name }}-{{ .Version }}
+set -e
+
+PACKAGE={{ shellquote .Primary }}
+SRC_ARCHIVE=${SRC_ARCHIVE:-/sources}
+BUILD_DIR=${BUILD_DIR:-/sources/build}
+
+as_root() {
+  if [ $EUID = 0 ]; then "$@"; else sudo -E "$@"; fi
+}
+
+mkdir -p "$SRC_ARCHIVE"
+cd "$SRC_ARCHIVE"
+{{ range .Sources }}if [ ! -f {{ shellquote .File }} ]; then
+  wget {{ shellquote .Archive }}
+fi
+{{ if .MD5Sum }}echo {{ shellquote (printf "%s  %s" .MD5Sum .File) }} | md5sum -c -
+{{ end }}{{ end }}
+mkdir -p "$BUILD_DIR"
+cd "$BUILD_DIR"
+{{ range .Sources }}ln -sf "$SRC_ARCHIVE"/{{ shellquote .File }} .
+{{ end }}{{ if .Primary }}PACKAGE_DIR=$({{ .SrcDirCmd }})
+rm -rf "$PACKAGE_DIR"
+tar -xf "$PACKAGE"
+cd "$PACKAGE_DIR"
+{{ end }}{{ range .Commands }}
+{{ if .Root }}as_root bash -e << 'ROOT_EOF'
+{{ range .Texts }}{{ . }}
+{{ end }}ROOT_EOF
+{{ else }}{{ range .Texts }}{{ . }}
+{{ end }}{{ end }}{{ end }}{{ if .Primary }}
+cd "$BUILD_DIR"
+rm -rf "$PACKAGE_DIR"
+{{ end }}exit
+`
+
+// jhalfsBookTemplate is the layout of the BLFS style DocBook book jhalfs reads
+// packages, downloads, dependencies and commands from
+const jhalfsBookTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE book PUBLIC "-//OASIS//DTD DocBook XML V4.5//EN"
+  "http://www.oasis-open.org/docbook/xml/4.5/docbookx.dtd">
+<!-- Generated by cmdext -->
+<book id="cmdext">
+  <bookinfo>
+    <title>{{ html .Title }}</title>
+  </bookinfo>
+  <part id="cmdext-packages">
+    <title>Packages</title>
+    <chapter id="cmdext-chapter">
+      <title>{{ html .Title }}</title>
+{{ range .Pages }}
+      <sect1 id="{{ .ID }}" xreflabel="{{ html .Name }}-{{ html .Version }}">
+        <sect1info>
+          <productname>{{ html .Name }}</productname>
+          <productnumber>{{ html .Version }}</productnumber>
+        </sect1info>
+        <title>{{ html .Name }}-{{ html .Version }}</title>
+        <sect2 role="package">
+          <title>Introduction to {{ html .Name }}</title>
+{{ if .Description }}          <para>{{ html .Description }}</para>
+{{ end }}{{ if .Sources }}          <bridgehead renderas="sect3">Package Information</bridgehead>
+          <itemizedlist spacing="compact">
+{{ with index .Sources 0 }}            <listitem>
+              <para>Download (HTTP): <ulink url="{{ html .Archive }}"/></para>
+            </listitem>
+{{ if .MD5Sum }}            <listitem>
+              <para>Download MD5 sum: {{ .MD5Sum }}</para>
+            </listitem>
+{{ end }}{{ end }}          </itemizedlist>
+{{ end }}{{ if gt (len .Sources) 1 }}          <bridgehead renderas="sect3">Additional Downloads</bridgehead>
+          <itemizedlist spacing="compact">
+{{ range slice .Sources 1 }}            <listitem>
+              <para>{{ if .Patch }}Required patch{{ else }}Download (HTTP){{ end }}: <ulink url="{{ html .Archive }}"/></para>
+            </listitem>
+{{ if .MD5Sum }}            <listitem>
+              <para>Download MD5 sum: {{ .MD5Sum }}</para>
+            </listitem>
+{{ end }}{{ end }}          </itemizedlist>
+{{ end }}{{ if or .Required .Recommended .Optional }}          <bridgehead renderas="sect3">{{ html .Name }} Dependencies</bridgehead>
+{{ if .Required }}          <bridgehead renderas="sect4">Required</bridgehead>
+          <para role="required">{{ range $i, $dep := .Required }}{{ if $i }}, {{ end }}{{ template "dep" $dep }}{{ end }}</para>
+{{ end }}{{ if .Recommended }}          <bridgehead renderas="sect4">Recommended</bridgehead>
+          <para role="recommended">{{ range $i, $dep := .Recommended }}{{ if $i }}, {{ end }}{{ template "dep" $dep }}{{ end }}</para>
+{{ end }}{{ if .Optional }}          <bridgehead renderas="sect4">Optional</bridgehead>
+          <para role="optional">{{ range $i, $dep := .Optional }}{{ if $i }}, {{ end }}{{ template "dep" $dep }}{{ end }}</para>
+{{ end }}{{ end }}        </sect2>
+        <sect2 role="installation">
+          <title>Installation of {{ html .Name }}</title>
+{{ range .Commands }}          <screen{{ if .Root }} role="root"{{ end }}><userinput>{{ html .Cmd }}</userinput></screen>
+{{ end }}        </sect2>
+      </sect1>
+{{ end }}
+    </chapter>
+  </part>
+</book>
+{{ define "dep" }}{{ if .ID }}<xref linkend="{{ .ID }}"/>{{ else }}{{ html .Text }}{{ end }}{{ end }}`
+
+// jhalfsBookFile is the name jhalfs gives the book it builds from
+const jhalfsBookFile = "blfs-full.xml"
+
+// jhalfsPage struct for jhalfspage
+type jhalfsPage struct {
+	ID          string
+	Name        string
+	Version     string
+	Description string
+	Sources     []jhalfsSource
+	Required    []jhalfsDep
+	Recommended []jhalfsDep
+	Optional    []jhalfsDep
+	Commands    []Command
+}
+
+// jhalfsDep struct for jhalfsdep
+// ID is the sect1 id of an exported dependency, others are only named by
+// Text.
+type jhalfsDep struct {
+	ID   string
+	Text string
+}
+
+// jhalfsBook struct for jhalfsbook
+type jhalfsBook struct {
+	Title string
+	Pages []jhalfsPage
+}
+
+// jhalfsSource struct for jhalfssource
+type jhalfsSource struct {
+	Archive string
+	File    string
+	MD5Sum  string
+	Patch   bool
+}
+
+// jhalfsCommand struct for jhalfscommand
+// Texts are the command and the cd ahead of it, if any.
+type jhalfsCommand struct {
+	Root  bool
+	Texts []string
+}
+
+// jhalfsData struct for jhalfsdata
+type jhalfsData struct {
+	*PackageInformation
+	Primary   string
+	SrcDirCmd string
+	Sources   []jhalfsSource
+	Commands  []jhalfsCommand
+}
+
+// jhalfsScriptName func takes n int, name string input and returns string
+// Scripts are numbered in build order like the ones jhalfs generates.
+func jhalfsScriptName(n int, name string) string {
+	return fmt.Sprintf("%03d-z-%s", n, name)
+}
+
+// JhalfsScript func takes pkgInfo *PackageInformation input and returns []byte, error
+// The script downloads and checks the sources, unpacks the primary source and
+// runs the commands, with root commands run through as_root. A cd is added
+// where a root command changed directory for the commands after it.
+func JhalfsScript(pkgInfo *PackageInformation) ([]byte, error) {
+	data := jhalfsData{PackageInformation: pkgInfo}
+	for _, src := range emitterSources(pkgInfo) {
+		data.Sources = append(data.Sources, jhalfsSource{Archive: src.Archive, File: sourceFile(src.Archive), MD5Sum: src.MD5Sum})
+	}
+	base := "$BUILD_DIR"
+	if data.Primary = unpackedArchive(pkgInfo); data.Primary != "" {
+		data.SrcDirCmd = sourceDirCommand(pkgInfo, `"$PACKAGE"`)
+		base = "$BUILD_DIR/$PACKAGE_DIR"
+	}
+	// root commands run in a shell of their own, their cd does not last
+	dirs := commandDirs(pkgInfo.Commands)
+	dir := "."
+	for _, cmd := range pkgInfo.Commands {
+		c := jhalfsCommand{Root: cmd.Root}
+		if cmd.Root {
+			c.Texts, _ = stageTexts([]Command{cmd}, dirs, base, dir)
+		} else {
+			c.Texts, dir = stageTexts([]Command{cmd}, dirs, base, dir)
+		}
+		data.Commands = append(data.Commands, c)
+	}
+	tmpl, err := ParseTemplate("jhalfs", jhalfsTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return Render(tmpl, data)
+}
+
+// jhalfsDeps func takes g *DependencyGraph, deps []string input and returns []jhalfsDep
+// Dependencies in the graph link to their sect1, whose id is the package key.
+// Those that are not exported keep their text, an xref to them would dangle.
+func jhalfsDeps(g *DependencyGraph, deps []string) []jhalfsDep {
+	jdeps := make([]jhalfsDep, 0, len(deps))
+	for _, dep := range deps {
+		if key := g.Key(dep); key != "" {
+			jdeps = append(jdeps, jhalfsDep{ID: stageName(key)})
+		} else {
+			jdeps = append(jdeps, jhalfsDep{Text: strings.TrimSpace(dep)})
+		}
+	}
+	return jdeps
+}
+
+// JhalfsBook func takes title string, pkgs []*PackageInformation input and returns []byte, error
+// The packages are written in build order as the sect1 pages of a BLFS style
+// DocBook book, in the markup jhalfs reads package names, versions,
+// downloads, dependencies and commands from. Root commands are in a screen
+// with the root role. Only dependencies in pkgs are linked.
+func JhalfsBook(title string, pkgs []*PackageInformation) ([]byte, error) {
+	book := jhalfsBook{Title: title}
+	g := NewDependencyGraph(pkgs, false)
+	keys := PackageKeys(pkgs)
+	for i, pkgInfo := range pkgs {
+		page := jhalfsPage{
+			ID:          stageName(keys[i]),
+			Name:        pkgInfo.Name,
+			Version:     pkgInfo.Version,
+			Description: strings.Join(strings.Fields(pkgInfo.Description), " "),
+			Required:    jhalfsDeps(g, pkgInfo.Dependencies.Requires),
+			Recommended: jhalfsDeps(g, pkgInfo.Dependencies.Recommended),
+			Optional:    jhalfsDeps(g, pkgInfo.Dependencies.Optional),
+			Commands:    sortedCommands(pkgInfo.Commands),
+		}
+		for _, src := range emitterSources(pkgInfo) {
+			page.Sources = append(page.Sources, jhalfsSource{Archive: src.Archive, File: sourceFile(src.Archive), MD5Sum: src.MD5Sum, Patch: isPatch(src.Archive)})
+		}
+		book.Pages = append(book.Pages, page)
+	}
+	tmpl, err := ParseTemplate("jhalfs book", jhalfsBookTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return Render(tmpl, book)
+}
+
+// ExportJhalfs func takes title string, pkgs []*PackageInformation input and returns []EmittedFile, error
+// pkgs must be in build order. Scripts are written to scripts/ and their
+// names, in order, to the order file. The packages are also written to the
+// DocBook book jhalfs builds from, titled title.
+func ExportJhalfs(title string, pkgs []*PackageInformation) ([]EmittedFile, error) {
+	files := make([]EmittedFile, 0, len(pkgs)+1)
+	order := make([]string, 0, len(pkgs))
+	for i, pkgInfo := range pkgs {
+		script, err := JhalfsScript(pkgInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create script for %s : %v", pkgInfo.Name, err)
+		}
+		name := jhalfsScriptName(i+1, pkgInfo.Name)
+		order = append(order, name)
+		files = append(files, EmittedFile{Path: path.Join("scripts", name), Content: script, Mode: 0755})
+	}
+	files = append(files, EmittedFile{Path: "order", Content: []byte(strings.Join(order, "\n") + "\n"), Mode: 0644})
+	book, err := JhalfsBook(title, pkgs)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, EmittedFile{Path: jhalfsBookFile, Content: book, Mode: 0644})
+	return files, nil
+}
+
+// loadCatalog func takes book, overrides string input and returns *Catalog, error
+// book is a catalog file, or the book's index.html or directory.
+func loadCatalog(book, overrides string) (*Catalog, error) {
+	if info, err := os.Stat(book); err == nil && !info.IsDir() {
+		switch strings.ToLower(filepath.Ext(book)) {
+		case ".json", ".yaml", ".yml":
+			catalog, err := ReadCatalog(book)
+			if err != nil {
+				return nil, err
+			}
+			return catalog, catalog.ApplyOverrides(overrides)
+		}
+	}
+	return CreateCatalog(book, overrides)
+}
+
+// chapterPackages func takes catalog *Catalog, chapter string input and returns []*PackageInformation, error
+// chapter matches a chapter title, case insensitively, or its page.
+func chapterPackages(catalog *Catalog, chapter string) ([]*PackageInformation, error) {
+	for _, c := range catalog.Chapters {
+		if !strings.EqualFold(c.Title, chapter) && c.Page != chapter {
+			continue
+		}
+		pkgs := make([]*PackageInformation, 0, len(c.Packages))
+		for _, entry := range c.Packages {
+			if entry.PackageInformation != nil {
+				pkgs = append(pkgs, entry.PackageInformation)
+			}
+		}
+		return pkgs, nil
+	}
+	return nil, fmt.Errorf("chapter %q not found in %s", chapter, catalog.Title)
+}
+
+// runJhalfs func takes args []string input and returns error
+func runJhalfs(args []string) error {
+	var (
+		output      string
+		chapter     string
+		overrides   string
+		recommended bool
+	)
+	flags := flag.NewFlagSet("jhalfs", flag.ExitOnError)
+	flags.StringVar(&output, "output", "jhalfs", "Directory to write the scripts, order file and book XML to")
+	flags.StringVar(&chapter, "chapter", "", "Export a chapter of the book in book order")
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&recommended, "recommended", false, "Include recommended dependencies")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s jhalfs [options] <book|catalog> [target...]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || (chapter == "" && flags.NArg() < 2) {
+		flags.Usage()
+		return fmt.Errorf("jhalfs requires a book and a chapter or target packages")
+	}
+	var pkgs []*PackageInformation
+	title := chapter
+	if chapter != "" {
+		catalog, err := loadCatalog(flags.Arg(0), overrides)
+		if err != nil {
+			return err
+		}
+		if pkgs, err = chapterPackages(catalog, chapter); err != nil {
+			return err
+		}
+	} else {
+		all, err := LoadPackages(flags.Arg(0), overrides)
+		if err != nil {
+			return err
+		}
+		pkgs = NewDependencyGraph(all, recommended).OrderedPackages(flags.Args()[1:])
+		title = strings.Join(flags.Args()[1:], " ")
+	}
+	if len(pkgs) == 0 {
+		return fmt.Errorf("no packages to export")
+	}
+	files, err := ExportJhalfs(title, pkgs)
+	if err != nil {
+		return err
+	}
+	return WriteEmittedFiles(output, files)
+}
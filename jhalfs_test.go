@@ -0,0 +1,156 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestJhalfsScript func takes no input and returns t *testing.T
+func TestJhalfsScript(t *testing.T) {
+	pkgInfo := &PackageInformation{
+		Name:    "tcl",
+		Version: "8.6.9",
+		Sources: []Source{
+			{Archive: "https://downloads.sourceforge.net/tcl/tcl8.6.9-src.tar.gz", MD5Sum: "aa0a121d95a0e7b73a036f26028538d4"},
+		},
+		Commands: []Command{
+			{Cmd: "cd unix &&\n./configure --prefix=/usr &&\nmake", Index: 0},
+			{Cmd: "make test", Index: 1},
+			{Cmd: "make install &&\nmake install-private-headers", Index: 2, Root: true},
+		},
+	}
+	content, err := JhalfsScript(pkgInfo)
+	assert.Assert(t, is.Nil(err))
+	script := string(content)
+	fmt.Printf("%s\n", script)
+	assert.Assert(t, strings.HasPrefix(script, "#!/bin/bash\n# Generated by cmdext from tcl-8.6.9\nset -e\n"))
+	assert.Assert(t, strings.Contains(script, "PACKAGE='tcl8.6.9-src.tar.gz'\n"))
+	assert.Assert(t, strings.Contains(script, "PACKAGE_DIR=$(tar -tf \"$PACKAGE\" | head -n 1 | sed -e 's@^\\./@@' -e 's@/.*@@')\nrm -rf \"$PACKAGE_DIR\"\n"))
+	assert.Assert(t, strings.Contains(script, "echo 'aa0a121d95a0e7b73a036f26028538d4  tcl8.6.9-src.tar.gz' | md5sum -c -\n"))
+	assert.Assert(t, strings.Contains(script, "\nmake test\n"))
+	assert.Assert(t, strings.Contains(script, "as_root bash -e << 'ROOT_EOF'\nmake install &&\n"))
+	assert.Assert(t, strings.HasSuffix(script, "rm -rf \"$PACKAGE_DIR\"\nexit\n"))
+
+	pkgInfo.SourceDir = "tcl8.6.9"
+	content, err = JhalfsScript(pkgInfo)
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.Contains(string(content), "PACKAGE_DIR=$(echo 'tcl8.6.9')\n"))
+}
+
+// TestJhalfsBook func takes no input and returns t *testing.T
+func TestJhalfsBook(t *testing.T) {
+	zlib := &PackageInformation{
+		Name:     "zlib",
+		Version:  "1.2.11",
+		Sources:  []Source{{Archive: "https://zlib.net/zlib-1.2.11.tar.xz", MD5Sum: "85adef240c5f370b308da8c938951a68"}},
+		Commands: []Command{{Cmd: "./configure --prefix=/usr &&\nmake", Index: 0}},
+	}
+	tcl := &PackageInformation{
+		Name:        "tcl",
+		Version:     "8.6.9",
+		Description: "The Tcl package contains the Tool Command Language.",
+		Sources: []Source{
+			{Archive: "https://downloads.sourceforge.net/tcl/tcl8.6.9-src.tar.gz", MD5Sum: "aa0a121d95a0e7b73a036f26028538d4"},
+			{Archive: "http://www.linuxfromscratch.org/patches/blfs/9.0/tcl-8.6.9-fix-1.patch"},
+		},
+		Dependencies: Dependencies{Requires: []string{"zlib-1.2.11"}, Optional: []string{"doxygen-1.8.16"}},
+		Commands: []Command{
+			{Cmd: "make install &&\nln -v -sf tclsh8.6 /usr/bin/tclsh", Index: 1, Root: true},
+			{Cmd: "./configure --prefix=/usr &&\nmake 2>&1 | tee log", Index: 0},
+		},
+	}
+	content, err := JhalfsBook("General Libraries", []*PackageInformation{zlib, tcl})
+	assert.Assert(t, is.Nil(err))
+	fmt.Printf("%s\n", content)
+
+	var book struct {
+		Title string `xml:"bookinfo>title"`
+		Pages []struct {
+			ID        string `xml:"id,attr"`
+			Name      string `xml:"sect1info>productname"`
+			Version   string `xml:"sect1info>productnumber"`
+			Sections2 []struct {
+				Role  string   `xml:"role,attr"`
+				Paras []string `xml:"itemizedlist>listitem>para"`
+				Deps  []struct {
+					Role  string `xml:"role,attr"`
+					Text  string `xml:",chardata"`
+					Xrefs []struct {
+						Linkend string `xml:"linkend,attr"`
+					} `xml:"xref"`
+				} `xml:"para"`
+				Screens []struct {
+					Role      string `xml:"role,attr"`
+					UserInput string `xml:"userinput"`
+				} `xml:"screen"`
+			} `xml:"sect2"`
+		} `xml:"part>chapter>sect1"`
+	}
+	err = xml.Unmarshal(content, &book)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, book.Title, "General Libraries")
+	assert.Equal(t, len(book.Pages), 2)
+	page := book.Pages[1]
+	assert.Equal(t, page.ID, "tcl")
+	assert.Equal(t, page.Name, "tcl")
+	assert.Equal(t, page.Version, "8.6.9")
+	assert.Assert(t, is.Contains(page.Sections2[0].Paras, "Download MD5 sum: aa0a121d95a0e7b73a036f26028538d4"))
+	assert.Assert(t, is.Contains(page.Sections2[0].Paras, "Required patch: "))
+	deps := page.Sections2[0].Deps
+	assert.Equal(t, deps[len(deps)-2].Role, "required")
+	assert.Equal(t, deps[len(deps)-2].Xrefs[0].Linkend, "zlib")
+	assert.Equal(t, deps[len(deps)-1].Role, "optional")
+	assert.Equal(t, deps[len(deps)-1].Text, "doxygen-1.8.16")
+	assert.Equal(t, len(deps[len(deps)-1].Xrefs), 0)
+	assert.Assert(t, !strings.Contains(string(content), `linkend="doxygen"`))
+	install := page.Sections2[1]
+	assert.Equal(t, install.Role, "installation")
+	assert.Equal(t, install.Screens[0].UserInput, "./configure --prefix=/usr &&\nmake 2>&1 | tee log")
+	assert.Equal(t, install.Screens[1].Role, "root")
+	assert.Assert(t, strings.Contains(string(content), "make 2&gt;&amp;1 | tee log"))
+}
+
+// TestRunJhalfs func takes no input and returns t *testing.T
+func TestRunJhalfs(t *testing.T) {
+	dir := writeBook(t, map[string]string{
+		"index.html":                testIndex,
+		"introduction/welcome.html": `<html><head><title>Welcome to BLFS</title></head><body></body></html>`,
+		"general/zlib.html":         testPage("zlib", "1.2.11", ""),
+		"general/tcl.html":          testPage("tcl", "8.6.9", `<a href="zlib.html">zlib-1.2.11</a>`),
+	})
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "chapter")
+	err := runJhalfs([]string{"-output", output, "-chapter", "general libraries", dir})
+	assert.Assert(t, is.Nil(err))
+	order, err := ioutil.ReadFile(filepath.Join(output, "order"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, string(order), "001-z-zlib\n002-z-tcl\n")
+	book, err := ioutil.ReadFile(filepath.Join(output, "blfs-full.xml"))
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.Contains(string(book), "<title>general libraries</title>"))
+	info, err := os.Stat(filepath.Join(output, "scripts", "002-z-tcl"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0755))
+
+	output = filepath.Join(dir, "closure")
+	err = runJhalfs([]string{"-output", output, filepath.Join(dir, "general"), "tcl"})
+	assert.Assert(t, is.Nil(err))
+	order, err = ioutil.ReadFile(filepath.Join(output, "order"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, string(order), "001-z-zlib\n002-z-tcl\n")
+
+	err = runJhalfs([]string{"-output", output, "-chapter", "Nope", dir})
+	assert.ErrorContains(t, err, "chapter \"Nope\" not found")
+}
@@ -0,0 +1,291 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+)
+
+// KernelOption struct for kerneloption
+// A kernel configuration option as the book shows it in menuconfig form.
+// State is y, m or n, or the value of a string or number option. m is met by
+// a built in option as well. Menu is the menuconfig path to the option.
+type KernelOption struct {
+	Symbol string   `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+	State  string   `json:"state" yaml:"state"`
+	Prompt string   `json:"prompt" yaml:"prompt"`
+	Menu   []string `json:"menu,omitempty" yaml:"menu,omitempty"`
+}
+
+// KernelRequirement struct for kernelrequirement
+// A KernelOption required by a set of packages.
+type KernelRequirement struct {
+	KernelOption `yaml:",inline"`
+	Packages     []string `json:"packages" yaml:"packages"`
+}
+
+// KernelMismatch struct for kernelmismatch
+// Found is the state of the option in the kernel configuration, n when unset.
+type KernelMismatch struct {
+	KernelRequirement `yaml:",inline"`
+	Found             string `json:"found" yaml:"found"`
+}
+
+var (
+	kernelState  = regexp.MustCompile(`^(\[[ *]\]|<[ *M/]*>|\{[ *M/]*\}|\([^)]*\)|-\*-)\s*`)
+	kernelSymbol = regexp.MustCompile(`\s*\[(CONFIG_[A-Za-z0-9_]+)\]\s*$`)
+	kernelMenu   = regexp.MustCompile(`\s*--->\s*$`)
+	kernelConfig = regexp.MustCompile(`^(CONFIG_[A-Za-z0-9_]+)=(.*)$`)
+	kernelUnset  = regexp.MustCompile(`^# (CONFIG_[A-Za-z0-9_]+) is not set$`)
+)
+
+// kernelRank orders the tristate states, a higher rank meets a lower one
+var kernelRank = map[string]int{"n": 0, "m": 1, "y": 2}
+
+// menuState func takes marker string input and returns string
+// [*], <*> and -*- are built in, <M> and <*/M> may be a module, [ ] and < >
+// are off and (value) is a value.
+func menuState(marker string) string {
+	inner := strings.TrimSpace(marker[1 : len(marker)-1])
+	switch {
+	case marker == "-*-":
+		return "y"
+	case strings.HasPrefix(marker, "("):
+		return inner
+	case strings.Contains(inner, "M"):
+		return "m"
+	case inner == "*":
+		return "y"
+	}
+	return "n"
+}
+
+// ParseKernelConfig func takes text string input and returns []KernelOption
+// text is a menuconfig screen from the book. Menus are nested by indentation
+// and end in --->; an option may be a menu as well.
+func ParseKernelConfig(text string) []KernelOption {
+	type menu struct {
+		indent int
+		title  string
+	}
+	var stack []menu
+	options := make([]KernelOption, 0)
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		option := KernelOption{}
+		if m := kernelSymbol.FindStringSubmatch(trimmed); m != nil {
+			option.Symbol = m[1]
+			trimmed = trimmed[:len(trimmed)-len(m[0])]
+		}
+		isMenu := kernelMenu.MatchString(trimmed)
+		trimmed = kernelMenu.ReplaceAllString(trimmed, "")
+		marker := kernelState.FindString(trimmed)
+		option.Prompt = strings.TrimSpace(trimmed[len(marker):])
+		if marker != "" || option.Symbol != "" {
+			option.State = "y"
+			if marker != "" {
+				option.State = menuState(strings.TrimSpace(marker))
+			}
+			for _, m := range stack {
+				option.Menu = append(option.Menu, m.title)
+			}
+			options = append(options, option)
+		}
+		if isMenu {
+			stack = append(stack, menu{indent: indent, title: option.Prompt})
+		}
+	}
+	return options
+}
+
+// ExtractKernelConfig func takes doc *goquery.Document input and returns []KernelOption
+func ExtractKernelConfig(doc *goquery.Document) []KernelOption {
+	options := make([]KernelOption, 0)
+	doc.Find(".kernel pre").Each(func(i int, s *goquery.Selection) {
+		options = append(options, ParseKernelConfig(s.Text())...)
+	})
+	return options
+}
+
+// KernelRequirements func takes pkgs []*PackageInformation input and returns []KernelRequirement, []error
+// Options are merged by symbol keeping the strongest state. Packages asking
+// for an option to be both on and off are reported. Options without a symbol
+// cannot be checked and are left out.
+func KernelRequirements(pkgs []*PackageInformation) ([]KernelRequirement, []error) {
+	var (
+		errs         []error
+		requirements []KernelRequirement
+		bySymbol     = make(map[string]int)
+	)
+	for _, pkgInfo := range pkgs {
+		for _, option := range pkgInfo.Kernel {
+			if option.Symbol == "" {
+				continue
+			}
+			i, ok := bySymbol[option.Symbol]
+			if !ok {
+				bySymbol[option.Symbol] = len(requirements)
+				requirements = append(requirements, KernelRequirement{KernelOption: option, Packages: []string{pkgInfo.Name}})
+				continue
+			}
+			r := &requirements[i]
+			if (r.State == "n") != (option.State == "n") {
+				errs = append(errs, fmt.Errorf("%s is %s for %s but %s for %s",
+					option.Symbol, r.State, strings.Join(r.Packages, ", "), option.State, pkgInfo.Name))
+			}
+			if kernelRank[option.State] > kernelRank[r.State] {
+				r.State = option.State
+			}
+			r.Packages = append(r.Packages, pkgInfo.Name)
+		}
+	}
+	sort.SliceStable(requirements, func(i, j int) bool {
+		return requirements[i].Symbol < requirements[j].Symbol
+	})
+	return requirements, errs
+}
+
+// ReadKernelConfig func takes file string input and returns map[string]string, error
+// Options that are not set are left out.
+func ReadKernelConfig(file string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	config := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := kernelConfig.FindStringSubmatch(line); m != nil {
+			config[m[1]] = strings.Trim(m[2], `"`)
+		} else if m := kernelUnset.FindStringSubmatch(line); m != nil {
+			delete(config, m[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read kernel config %s : %v", file, err)
+	}
+	return config, nil
+}
+
+// CheckKernelConfig func takes config map[string]string, requirements []KernelRequirement input and returns []KernelMismatch
+func CheckKernelConfig(config map[string]string, requirements []KernelRequirement) []KernelMismatch {
+	mismatches := make([]KernelMismatch, 0)
+	for _, r := range requirements {
+		found, ok := config[r.Symbol]
+		if !ok {
+			found = "n"
+		}
+		var met bool
+		switch r.State {
+		case "y":
+			met = found == "y"
+		case "m":
+			met = found == "y" || found == "m"
+		case "n":
+			met = found == "n"
+		default:
+			met = found == r.State
+		}
+		if !met {
+			mismatches = append(mismatches, KernelMismatch{KernelRequirement: r, Found: found})
+		}
+	}
+	return mismatches
+}
+
+// kernelText func takes mismatches []KernelMismatch input and returns []byte
+func kernelText(mismatches []KernelMismatch) []byte {
+	var buf bytes.Buffer
+	for _, m := range mismatches {
+		fmt.Fprintf(&buf, "%s=%s found %s (%s)\n", m.Symbol, m.State, m.Found, strings.Join(m.Packages, ", "))
+		path := append(append([]string(nil), m.Menu...), m.Prompt)
+		fmt.Fprintf(&buf, "    %s\n", strings.Join(path, " ---> "))
+	}
+	return buf.Bytes()
+}
+
+// runKernel func takes args []string input and returns error
+func runKernel(args []string) error {
+	var (
+		configFile  string
+		overrides   string
+		asjson      bool
+		recommended bool
+	)
+	flags := flag.NewFlagSet("kernel", flag.ExitOnError)
+	flags.StringVar(&configFile, "config", "", "Check this kernel .config against the requirements")
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&asjson, "json", false, "Output JSON")
+	flags.BoolVar(&recommended, "recommended", false, "Include recommended dependencies")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s kernel [options] <book|package> [target...]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return fmt.Errorf("kernel requires a book or package")
+	}
+	pkgs, err := LoadPackages(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	pkgs = NewDependencyGraph(pkgs, recommended).OrderedPackages(flags.Args()[1:])
+	requirements, errs := KernelRequirements(pkgs)
+	for _, err := range errs {
+		warn(err)
+	}
+	var result interface{} = requirements
+	var mismatches []KernelMismatch
+	if configFile != "" {
+		config, err := ReadKernelConfig(configFile)
+		if err != nil {
+			return err
+		}
+		mismatches = CheckKernelConfig(config, requirements)
+		result = mismatches
+	}
+	switch {
+	case asjson:
+		jsn, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to convert to json : %v", err)
+		}
+		fmt.Printf("%s\n", jsn)
+	case configFile != "":
+		fmt.Printf("%s", kernelText(mismatches))
+	default:
+		yml, err := yaml.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to convert to yaml : %v", err)
+		}
+		fmt.Printf("%s", yml)
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%d kernel options do not match %s", len(mismatches), configFile)
+	}
+	return nil
+}
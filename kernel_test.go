@@ -0,0 +1,91 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// kernelPage is a page with a Kernel Configuration section
+const kernelPage = `<html><body>
+<div class="kernel">
+<h2 class="sect2">Kernel Configuration</h2>
+<pre class="screen"><code class="literal">File systems ---&gt;
+  [*] Network File Systems ---&gt;                              [CONFIG_NETWORK_FILESYSTEMS]
+    &lt;*/M&gt; NFS client support                                [CONFIG_NFS_FS]
+    &lt;*&gt;   NFS server support                                [CONFIG_NFSD]
+  &lt; &gt; Quota support                                         [CONFIG_QUOTA]
+General setup ---&gt;
+  (-lfs) Local version - append to kernel release           [CONFIG_LOCALVERSION]
+</code></pre>
+</div>
+</body></html>`
+
+// TestExtractKernelConfig func takes no input and returns t *testing.T
+func TestExtractKernelConfig(t *testing.T) {
+	doc, err := ReadDoc([]byte(kernelPage))
+	assert.Assert(t, is.Nil(err))
+	options := ExtractKernelConfig(doc)
+	assert.DeepEqual(t, options, []KernelOption{
+		{Symbol: "CONFIG_NETWORK_FILESYSTEMS", State: "y", Prompt: "Network File Systems", Menu: []string{"File systems"}},
+		{Symbol: "CONFIG_NFS_FS", State: "m", Prompt: "NFS client support", Menu: []string{"File systems", "Network File Systems"}},
+		{Symbol: "CONFIG_NFSD", State: "y", Prompt: "NFS server support", Menu: []string{"File systems", "Network File Systems"}},
+		{Symbol: "CONFIG_QUOTA", State: "n", Prompt: "Quota support", Menu: []string{"File systems"}},
+		{Symbol: "CONFIG_LOCALVERSION", State: "-lfs", Prompt: "Local version - append to kernel release", Menu: []string{"General setup"}},
+	})
+}
+
+// TestCheckKernelConfig func takes no input and returns t *testing.T
+func TestCheckKernelConfig(t *testing.T) {
+	nfs := &PackageInformation{Name: "nfs-utils", Kernel: []KernelOption{
+		{Symbol: "CONFIG_NFS_FS", State: "m"},
+		{Symbol: "CONFIG_NFSD", State: "y"},
+		{Symbol: "CONFIG_QUOTA", State: "n"},
+		{State: "y", Prompt: "Without a symbol"},
+	}}
+	autofs := &PackageInformation{Name: "autofs", Kernel: []KernelOption{
+		{Symbol: "CONFIG_NFS_FS", State: "y"},
+		{Symbol: "CONFIG_QUOTA", State: "y"},
+	}}
+	requirements, errs := KernelRequirements([]*PackageInformation{nfs, autofs})
+	assert.Equal(t, len(errs), 1)
+	assert.ErrorContains(t, errs[0], "CONFIG_QUOTA is n for nfs-utils but y for autofs")
+	assert.Equal(t, len(requirements), 3)
+	assert.Equal(t, requirements[0].Symbol, "CONFIG_NFSD")
+	assert.Equal(t, requirements[1].State, "y")
+	assert.DeepEqual(t, requirements[1].Packages, []string{"nfs-utils", "autofs"})
+
+	dir, err := ioutil.TempDir("", "kernel")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(dir)
+	config := filepath.Join(dir, ".config")
+	err = ioutil.WriteFile(config, []byte(`#
+# Automatically generated file; DO NOT EDIT.
+#
+CONFIG_NFS_FS=m
+CONFIG_NFSD=y
+# CONFIG_QUOTA is not set
+CONFIG_LOCALVERSION="-lfs"
+`), 0644)
+	assert.Assert(t, is.Nil(err))
+	found, err := ReadKernelConfig(config)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, found["CONFIG_LOCALVERSION"], "-lfs")
+
+	mismatches := CheckKernelConfig(found, requirements)
+	assert.Equal(t, len(mismatches), 2)
+	assert.Equal(t, mismatches[0].Symbol, "CONFIG_NFS_FS")
+	assert.Equal(t, mismatches[0].Found, "m")
+	assert.Equal(t, mismatches[1].Symbol, "CONFIG_QUOTA")
+	assert.Equal(t, mismatches[1].Found, "n")
+
+	met := CheckKernelConfig(found, []KernelRequirement{{KernelOption: KernelOption{Symbol: "CONFIG_NFS_FS", State: "m"}}})
+	assert.Equal(t, len(met), 0)
+}
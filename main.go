@@ -7,11 +7,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
-	"strconv"
 	"strings"
+	"text/template"
 
 	"flag"
 
@@ -21,18 +22,38 @@ import (
 
 // PackageInformation struct for packageinformation
 type PackageInformation struct {
-	Commands     []Command    `json:"commands" yaml:"commands"`
-	Dependencies Dependencies `json:"dependencies" yaml:"dependencies"`
-	Description  string       `json:"description" yaml:"description"`
-	Name         string       `json:"name" yaml:"name"`
-	Sources      []Source     `json:"sources" yaml:"sources"`
-	Version      string       `json:"version" yaml:"version"`
+	Accounts       []Account      `json:"accounts,omitempty" yaml:"accounts,omitempty"`
+	Bootscripts    []Bootscript   `json:"bootscripts,omitempty" yaml:"bootscripts,omitempty"`
+	Commands       []Command      `json:"commands" yaml:"commands"`
+	ConfigFiles    []ConfigFile   `json:"config_files,omitempty" yaml:"config_files,omitempty"`
+	Contents       *Contents      `json:"contents,omitempty" yaml:"contents,omitempty"`
+	Confidence     string         `json:"confidence,omitempty" yaml:"confidence,omitempty"`
+	Dependencies   Dependencies   `json:"dependencies" yaml:"dependencies"`
+	Description    string         `json:"description" yaml:"description"`
+	Diagnostics    []string       `json:"diagnostics,omitempty" yaml:"diagnostics,omitempty"`
+	Explanations   []Explanation  `json:"explanations,omitempty" yaml:"explanations,omitempty"`
+	Fixups         []SourceFixup  `json:"fixups,omitempty" yaml:"fixups,omitempty"`
+	Kernel         []KernelOption `json:"kernel,omitempty" yaml:"kernel,omitempty"`
+	Name           string         `json:"name" yaml:"name"`
+	Options        []BuildOption  `json:"options,omitempty" yaml:"options,omitempty"`
+	Overrides      []string       `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Path           string         `json:"path,omitempty" yaml:"path,omitempty"`
+	SourceDir      string         `json:"source_dir,omitempty" yaml:"source_dir,omitempty"`
+	Sources        []Source       `json:"sources" yaml:"sources"`
+	Version        string         `json:"version" yaml:"version"`
+	VersionGuessed bool           `json:"version_guessed,omitempty" yaml:"version_guessed,omitempty"`
 }
 
 // Command struct for command
 type Command struct {
-	Cmd   string `json:"cmd" yaml:"cmd"`
-	Index int    `json:"index" yaml:"index"`
+	Cmd     string `json:"cmd" yaml:"cmd"`
+	Index   int    `json:"index" yaml:"index"`
+	Root    bool   `json:"root,omitempty" yaml:"root,omitempty"`
+	Section string `json:"section,omitempty" yaml:"section,omitempty"`
+	// Fixup is set when the command only edits or patches the source
+	Fixup bool `json:"fixup,omitempty" yaml:"fixup,omitempty"`
+	// StagingIssues lists writes outside of DESTDIR that could not be rewritten
+	StagingIssues []string `json:"staging_issues,omitempty" yaml:"staging_issues,omitempty"`
 }
 
 // Dependencies struct for dependencies
@@ -51,11 +72,21 @@ type Source struct {
 	Size      string `json:"size" yaml:"size"`
 }
 
+// Contents struct for contents
+type Contents struct {
+	Programs    []string `json:"programs" yaml:"programs"`
+	Libraries   []string `json:"libraries" yaml:"libraries"`
+	Directories []string `json:"directories" yaml:"directories"`
+}
+
 // Application struct for application
 type Application struct {
-	Name        string `json:"name" yaml:"name"`
-	Description string `json:"description" yaml:"description"`
-	Version     string `json:"version" yaml:"version"`
+	Name           string   `json:"name" yaml:"name"`
+	Description    string   `json:"description" yaml:"description"`
+	Version        string   `json:"version" yaml:"version"`
+	VersionGuessed bool     `json:"version_guessed" yaml:"version_guessed"`
+	Confidence     string   `json:"confidence" yaml:"confidence"`
+	Diagnostics    []string `json:"diagnostics" yaml:"diagnostics"`
 }
 
 // ToYAML func takes no input and returns []byte, error
@@ -85,17 +116,58 @@ func (p *PackageInformation) ToPrettyJSON() ([]byte, error) {
 	return content, nil
 }
 
-// end func takes string splits it on separator and returns string
-func end(s, sep string) string {
-	ss := strings.Split(s, sep)
-	return ss[len(ss)-1]
+// streamFilename is the file -ndjson writes to with -write-to-disk
+const streamFilename = "packages.ndjson"
+
+// WriteStream func takes w io.Writer, pkgInfo *PackageInformation, ndjson bool input and returns error
+// The package is written as one line of newline delimited JSON, or as a YAML
+// document starting with --- so several packages form a multi-document stream.
+func WriteStream(w io.Writer, pkgInfo *PackageInformation, ndjson bool) error {
+	var (
+		content []byte
+		err     error
+	)
+	if ndjson {
+		content, err = pkgInfo.ToJSON()
+		content = append(content, '\n')
+	} else {
+		content, err = pkgInfo.ToYAML()
+		content = append([]byte("---\n"), content...)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s : %v", pkgInfo.Name, err)
+	}
+	return nil
+}
+
+// ParsedVersion func takes no input and returns Version
+func (p *PackageInformation) ParsedVersion() Version {
+	version := MustParseVersion(p.Version)
+	if p.VersionGuessed {
+		version.Guessed = true
+	}
+	return version
 }
 
-// begin func takes string splits it on separator and returns string
-func begin(s, sep string) string {
-	ss := strings.Split(s, sep)
-	ss = ss[:len(ss)-1]
-	return strings.Join(ss, sep)
+// commandSections are the page sections commands are found in
+var commandSections = []string{"installation", "configuration", "kernel", "package", "commands"}
+
+// commandSection func takes s *goquery.Selection input and returns string
+func commandSection(s *goquery.Selection) string {
+	section := ""
+	s.ParentsFiltered("div").EachWithBreak(func(i int, div *goquery.Selection) bool {
+		for _, name := range commandSections {
+			if div.HasClass(name) {
+				section = name
+				return false
+			}
+		}
+		return true
+	})
+	return section
 }
 
 // ExtractCommands func takes doc *goquery.Document input and returns []Command, error
@@ -104,8 +176,10 @@ func ExtractCommands(doc *goquery.Document) ([]Command, error) {
 	var index int
 	doc.Find("kbd").Each(func(i int, s *goquery.Selection) {
 		command := Command{
-			Index: index,
-			Cmd:   s.Text(),
+			Index:   index,
+			Cmd:     s.Text(),
+			Root:    s.ParentsFiltered("pre").First().HasClass("root"),
+			Section: commandSection(s),
 		}
 		commands = append(commands, command)
 		index++
@@ -127,6 +201,9 @@ func ExtractSources(doc *goquery.Document) ([]Source, error) {
 			case strings.Contains(block, "(HTTP):"):
 				link := s.Find(".ulink").Text()
 				source.Archive = strings.TrimSpace(link)
+			case strings.Contains(block, "Required patch:"):
+				link := s.Find(".ulink").Text()
+				sources = append(sources, Source{Archive: strings.TrimSpace(link)})
 			case strings.Contains(block, "MD5 sum:"):
 				md5 := strings.Split(block, ":")[1]
 				source.MD5Sum = strings.TrimSpace(md5)
@@ -151,35 +228,32 @@ func ExtractSources(doc *goquery.Document) ([]Source, error) {
 
 // ExtractApplication func takes doc *goquery.Document input and returns Application, error
 func ExtractApplication(doc *goquery.Document) (Application, error) {
+	return ExtractApplicationFromPage(doc, "")
+}
+
+// ExtractApplicationFromPage func takes doc *goquery.Document, page string input and returns Application, error
+// The name and version come from the page title, falling back to the h1.sect1
+// heading. The h1.sect1 anchor id and the page filename are used to
+// corroborate the name.
+func ExtractApplicationFromPage(doc *goquery.Document, page string) (Application, error) {
 	application := Application{}
-	doc.Find("title").Each(func(i int, s *goquery.Selection) {
-		titlestr := s.Text()
-		if titlestr != "" {
-			title := strings.TrimSpace(titlestr)
-			name := begin(title, "-")
-			version := end(title, "-")
-			if strings.Contains(title, " ") {
-				name = strings.Join(strings.Split(title, " "), "-")
-				version = "1.0.0"
-			}
-			if strings.Contains(title, "&nbsp;") {
-				t := end(title, "&nbsp;")
-				name = begin(t, "-")
-				version = end(t, "-")
-			}
-			title = strconv.QuoteToASCII(strings.TrimSpace(titlestr))
-			if strings.Contains(title, "\\u00a0") {
-				t := end(title, "\\u00a0")
-				name = begin(t, "-")
-				version = end(t, "-")
-				if strings.HasSuffix(version, `"`) {
-					version = version[:len(version)-1]
-				}
-			}
-			application.Name = strings.ToLower(strings.TrimSpace(name))
-			application.Version = strings.TrimSpace(version)
+	title := cleanTitle(doc.Find("title").First().Text())
+	heading := doc.Find("h1.sect1").First()
+	anchor, _ := heading.Find("a[id]").First().Attr("id")
+	name, version := splitNameVersion(title)
+	if h1 := cleanTitle(heading.Text()); h1 != "" {
+		h1Name, h1Version := splitNameVersion(h1)
+		switch {
+		case title == "" || (version == "" && h1Version != ""):
+			name, version = h1Name, h1Version
+		case h1Version != "" && h1Version != version:
+			application.Diagnostics = append(application.Diagnostics,
+				fmt.Sprintf("title version %q does not match heading version %q", version, h1Version))
 		}
-	})
+	}
+	application.Name = normalizeName(name)
+	application.Version = version
+	application.VersionGuessed = application.Name != "" && application.Version == ""
 
 	doc.Find(".package").Each(func(i int, s *goquery.Selection) {
 		if application.Name == "" {
@@ -194,11 +268,54 @@ func ExtractApplication(doc *goquery.Document) (Application, error) {
 		return application, fmt.Errorf("application name empty")
 	}
 
-	if application.Version == "" {
-		return application, fmt.Errorf("application version empty")
+	corroborate(&application, anchor, page)
+	return application, nil
+}
+
+// splitSegBody func takes body string input and returns []string
+// Segment bodies list items as "a, b, and c"; "None" yields an empty list.
+func splitSegBody(body string) []string {
+	items := make([]string, 0)
+	body = strings.Join(strings.Fields(body), " ")
+	body = strings.ReplaceAll(body, ", and ", ", ")
+	body = strings.ReplaceAll(body, " and ", ", ")
+	for _, item := range strings.Split(body, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || strings.EqualFold(item, "none") {
+			continue
+		}
+		items = append(items, item)
 	}
+	return items
+}
 
-	return application, nil
+// ExtractContents func takes doc *goquery.Document input and returns Contents, error
+func ExtractContents(doc *goquery.Document) (Contents, error) {
+	contents := Contents{
+		Programs:    []string{},
+		Libraries:   []string{},
+		Directories: []string{},
+	}
+	found := false
+	doc.Find(".content .segmentedlist .seg").Each(func(i int, s *goquery.Selection) {
+		title := strings.ToLower(s.Find(".segtitle").Text())
+		body := splitSegBody(s.Find(".segbody").Text())
+		switch {
+		case strings.Contains(title, "program"):
+			contents.Programs = append(contents.Programs, body...)
+		case strings.Contains(title, "librar"):
+			contents.Libraries = append(contents.Libraries, body...)
+		case strings.Contains(title, "director"):
+			contents.Directories = append(contents.Directories, body...)
+		default:
+			return
+		}
+		found = true
+	})
+	if !found {
+		return contents, fmt.Errorf("contents not found")
+	}
+	return contents, nil
 }
 
 // ReadDoc func takes b []byte input and returns *goquery.Document, error
@@ -243,36 +360,115 @@ func ExtractDependencies(doc *goquery.Document) (Dependencies, error) {
 
 // CreatePackageInformation func takes b []byte input and returns *PackageInformation, error
 func CreatePackageInformation(b []byte) (*PackageInformation, error) {
-	pkgInfo := &PackageInformation{}
+	return CreatePackageInformationFromPage(b, "")
+}
+
+// CreatePackageInformationFromPage func takes b []byte, page string input and returns *PackageInformation, error
+// page is the path of the HTML page and is used to corroborate the package name.
+func CreatePackageInformationFromPage(b []byte, page string) (*PackageInformation, error) {
 	doc, err := ReadDoc(b)
 	if err != nil {
-		return pkgInfo, err
+		return &PackageInformation{}, err
 	}
+	return PackageInformationFromDoc(doc, page)
+}
+
+// PackageInformationFromDoc func takes doc *goquery.Document, page string input and returns *PackageInformation, error
+func PackageInformationFromDoc(doc *goquery.Document, page string) (*PackageInformation, error) {
+	pkgInfo := &PackageInformation{}
 	deps, err := ExtractDependencies(doc)
 	if err != nil {
-		fmt.Printf("WARNING : %s\n", err)
+		warn(err)
 	}
 	pkgInfo.Dependencies = deps
 	cmds, err := ExtractCommands(doc)
 	if err != nil {
-		fmt.Printf("WARNING : %s\n", err)
+		warn(err)
 	}
 	pkgInfo.Commands = cmds
+	pkgInfo.Explanations = ExtractExplanations(doc, cmds)
+	pkgInfo.Options = ExtractOptions(cmds, pkgInfo.Explanations)
+	pkgInfo.ConfigFiles = ExtractConfigFiles(doc, cmds)
+	pkgInfo.Bootscripts = ExtractBootscripts(doc, cmds)
+	pkgInfo.Accounts = ExtractAccounts(cmds)
 	srcs, err := ExtractSources(doc)
 	if err != nil {
-		fmt.Printf("WARNING : %s\n", err)
+		warn(err)
 	}
 	pkgInfo.Sources = srcs
-	app, err := ExtractApplication(doc)
+	pkgInfo.Fixups = ExtractFixups(pkgInfo)
+	pkgInfo.Kernel = ExtractKernelConfig(doc)
+	contents, contentsErr := ExtractContents(doc)
+	if contentsErr == nil {
+		pkgInfo.Contents = &contents
+	}
+	app, err := ExtractApplicationFromPage(doc, page)
 	if err != nil {
-		fmt.Printf("WARNING : %s\n", err)
+		warn(err)
 	}
 	pkgInfo.Name = app.Name
 	pkgInfo.Version = app.Version
+	pkgInfo.VersionGuessed = app.VersionGuessed
+	pkgInfo.Confidence = app.Confidence
+	pkgInfo.Diagnostics = app.Diagnostics
+	if contentsErr != nil {
+		pkgInfo.Diagnostics = append(pkgInfo.Diagnostics, contentsErr.Error())
+	}
 	pkgInfo.Description = app.Description
+	for _, patch := range resolvePatches(pkgInfo, ExtractPatches(doc)) {
+		warn(fmt.Errorf("patch %s of %s has no matching source", patch, pkgInfo.Name))
+	}
 	return pkgInfo, nil
 }
 
+// deriveFields func takes pkgInfo *PackageInformation input
+// The fields worked out from the commands, Explanations' commands, Options,
+// ConfigFiles, Bootscripts, Accounts and Fixups, are derived again from
+// pkgInfo.Commands. What only the page gives is kept: the explanation texts,
+// the config files named in the text and the boot scripts package. Overrides
+// call it once they have changed the commands or sources.
+func deriveFields(pkgInfo *PackageInformation) {
+	cmds := pkgInfo.Commands
+	argvs := commandArgvs(cmds)
+	explanations := make([]Explanation, 0, len(pkgInfo.Explanations))
+	for _, explanation := range pkgInfo.Explanations {
+		explanation.Commands = explainedCommands(explanation.Term, cmds, argvs)
+		explanations = append(explanations, explanation)
+	}
+	pkgInfo.Explanations = explanations
+	pkgInfo.Options = ExtractOptions(cmds, explanations)
+	files := heredocFiles(cmds)
+	seen := make(map[string]bool)
+	for _, file := range files {
+		seen[file.Path] = true
+	}
+	for _, file := range pkgInfo.ConfigFiles {
+		if file.Command < 0 && !seen[file.Path] {
+			seen[file.Path] = true
+			files = append(files, file)
+		}
+	}
+	pkgInfo.ConfigFiles = files
+	pkg := ""
+	if len(pkgInfo.Bootscripts) > 0 {
+		pkg = pkgInfo.Bootscripts[0].Package
+	}
+	pkgInfo.Bootscripts = commandBootscripts(cmds, pkg)
+	pkgInfo.Accounts = ExtractAccounts(cmds)
+	for i := range cmds {
+		cmds[i].Fixup = false
+	}
+	pkgInfo.Fixups = ExtractFixups(pkgInfo)
+	for _, patch := range resolvePatches(pkgInfo, nil) {
+		warn(fmt.Errorf("patch %s of %s has no matching source", patch, pkgInfo.Name))
+	}
+}
+
+// warn func takes err error input and writes it to stderr
+func warn(err error) {
+	fmt.Fprintf(os.Stderr, "WARNING : %s\n", err)
+}
+
 func check(err error) {
 	if err != nil {
 		fmt.Println(err)
@@ -280,49 +476,167 @@ func check(err error) {
 	}
 }
 
+// commands maps subcommand names to their entry points
+var commands = map[string]func(args []string) error{
+	"accounts":   runAccounts,
+	"catalog":    runCatalog,
+	"diff":       runDiff,
+	"dockerfile": runDockerfile,
+	"jhalfs":     runJhalfs,
+	"kernel":     runKernel,
+	"makefile":   runMakefile,
+	"retarget":   runRetarget,
+	"run":        runRun,
+	"sqlite":     runSQLite,
+	"steps":      runSteps,
+	"upgrade":    runUpgrade,
+}
+
 // main func takes no input and returns
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			check(cmd(os.Args[2:]))
+			return
+		}
+	}
 	var (
-		destdir  string
-		asjson   bool
-		asyaml   bool
-		noindent bool
-		write    bool
-		debug    bool
+		destdir      string
+		override     string
+		tmplFile     string
+		emitName     string
+		sourceCache  string
+		stageDir     string
+		nameTmplText string
+		emitDate     string
+		asjson       bool
+		asyaml       bool
+		noindent     bool
+		ndjson       bool
+		tmplAll      bool
+		write        bool
+		debug        bool
 	)
 	flag.StringVar(&destdir, "destination", "/tmp/pkgs", "Path to write files to disk")
+	flag.StringVar(&override, "overrides", "", "Path to a directory of package override files")
+	flag.StringVar(&tmplFile, "template", "", "Render output through a Go text/template file")
+	flag.StringVar(&emitName, "emit", "", "Emit a package build file ("+strings.Join(EmitterNames(), ", ")+")")
+	flag.StringVar(&stageDir, "destdir", "", "Stage install commands of -emit output into this DESTDIR, in place of the emitter's default")
+	flag.StringVar(&sourceCache, "source-cache", "", "Path to a directory of downloaded source archives")
+	flag.StringVar(&nameTmplText, "filename-template", "", "Go text/template for file names written to disk")
+	flag.StringVar(&emitDate, "date", "", "Date emitted files record as YYYY-MM-DD, default SOURCE_DATE_EPOCH or the page's modification time")
 	flag.BoolVar(&asjson, "json", false, "Output JSON")
 	flag.BoolVar(&asyaml, "yaml", true, "Output YAML (default)")
 	flag.BoolVar(&noindent, "noindent", false, "No Indent for JSON")
+	flag.BoolVar(&ndjson, "ndjson", false, "Output newline delimited JSON, one package per line")
+	flag.BoolVar(&tmplAll, "template-all", false, "Render the template once with the list of all packages")
 	flag.BoolVar(&write, "write-to-disk", false, "Write files to disk")
 	flag.BoolVar(&debug, "debug", false, "Turn debugging on")
 	flag.Parse()
 	args := flag.Args()
-	if asjson {
+	var (
+		tmpl     *template.Template
+		nameTmpl *template.Template
+		emitter  *Emitter
+		err      error
+	)
+	if emitName != "" {
+		e, err := GetEmitter(emitName)
+		check(err)
+		emitter = &e
+		asjson, ndjson = false, false
+	}
+	if stageDir != "" && emitter == nil {
+		check(fmt.Errorf("-destdir requires -emit, extracted commands are written as the book has them"))
+	}
+	if tmplFile != "" {
+		tmpl, err = ReadTemplate(tmplFile)
+		check(err)
+		asjson, ndjson = false, false
+	}
+	if nameTmplText != "" {
+		nameTmpl, err = ParseTemplate("filename", nameTmplText)
+		check(err)
+	}
+	if asjson || ndjson || tmpl != nil || emitter != nil {
 		asyaml = false
 	}
+	if ndjson {
+		asjson = false
+	}
+	stream := ndjson || len(args) > 1
+	// output writes content to disk under the templated file name or to stdout
+	output := func(data interface{}, content []byte, filename string, mode os.FileMode) {
+		if !write {
+			fmt.Printf("%s\n", content)
+			return
+		}
+		filename, err := OutputFilename(nameTmpl, data, filename)
+		check(err)
+		filepath := path.Join(destdir, filename)
+		err = os.MkdirAll(path.Dir(filepath), 0755)
+		check(err)
+		err = ioutil.WriteFile(filepath, content, mode)
+		check(err)
+	}
 	if len(args) > 0 {
+		var streamOut io.Writer = os.Stdout
 		if write {
 			err := os.MkdirAll(destdir, 0755)
 			check(err)
+			if ndjson {
+				f, err := os.Create(path.Join(destdir, streamFilename))
+				check(err)
+				defer f.Close()
+				streamOut = f
+			}
 		}
+		pkgs := make([]*PackageInformation, 0, len(args))
 		for _, filepath := range args {
 			b, err := ioutil.ReadFile(filepath)
 			check(err)
-			pkgInfo, err := CreatePackageInformation(b)
+			pkgInfo, err := CreatePackageInformationFromPage(b, filepath)
 			check(err)
-			if asyaml {
-				yml, err := pkgInfo.ToYAML()
+			err = ApplyOverrides(pkgInfo, override)
+			check(err)
+			if stream {
+				pkgInfo.Path = filepath
+			}
+			pkgs = append(pkgs, pkgInfo)
+			if ndjson {
+				err = WriteStream(streamOut, pkgInfo, true)
+				check(err)
+			}
+			if tmpl != nil && !tmplAll {
+				content, err := Render(tmpl, pkgInfo)
+				check(err)
+				output(pkgInfo, content, DefaultFilename(pkgInfo, templateExtension(tmplFile)), 0644)
+			}
+			if emitter != nil {
+				date, err := SourceDate(emitDate, filepath)
 				check(err)
-				if write {
-					filename := pkgInfo.Name + "-" + pkgInfo.Version + ".yaml"
-					filepath := path.Join(destdir, filename)
-					err := ioutil.WriteFile(filepath, yml, 0644) //nolint:gosec
+				files, err := emitter.Emit(pkgInfo, EmitOptions{SourceCache: sourceCache, Destdir: stageDir, Date: date})
+				check(err)
+				switch {
+				case len(files) == 1:
+					output(pkgInfo, files[0].Content, files[0].Path, files[0].Mode)
+				case write:
+					err = WriteEmittedFiles(destdir, files)
 					check(err)
-				} else {
-					fmt.Printf("%s\n", yml)
+				default:
+					for _, file := range files {
+						fmt.Printf("# ==> %s <==\n%s\n", file.Path, file.Content)
+					}
 				}
 			}
+			if asyaml && stream && !write {
+				err = WriteStream(streamOut, pkgInfo, false)
+				check(err)
+			} else if asyaml {
+				yml, err := pkgInfo.ToYAML()
+				check(err)
+				output(pkgInfo, yml, DefaultFilename(pkgInfo, ".yaml"), 0644)
+			}
 			if asjson {
 				jsn, err := pkgInfo.ToPrettyJSON()
 				check(err)
@@ -330,14 +644,16 @@ func main() {
 					jsn, err = pkgInfo.ToJSON()
 					check(err)
 				}
-				if write {
-					filename := pkgInfo.Name + "-" + pkgInfo.Version + ".json"
-					filepath := path.Join(destdir, filename)
-					err := ioutil.WriteFile(filepath, jsn, 0644) // nolint:gosec
-					check(err)
-				} else {
-					fmt.Printf("%s\n", jsn)
-				}
+				output(pkgInfo, jsn, DefaultFilename(pkgInfo, ".json"), 0644)
+			}
+		}
+		if tmpl != nil && tmplAll {
+			content, err := Render(tmpl, pkgs)
+			check(err)
+			if write {
+				output(pkgs, content, strings.TrimSuffix(path.Base(tmplFile), ".tmpl"), 0644)
+			} else {
+				fmt.Printf("%s", content)
 			}
 		}
 	}
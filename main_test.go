@@ -4,9 +4,14 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"testing"
 
+	"gopkg.in/yaml.v3"
 	"gotest.tools/assert"
 	is "gotest.tools/assert/cmp"
 )
@@ -313,6 +318,14 @@ make</kbd>
 	for _, opt := range deps.Optional {
 		fmt.Printf("OPTIONAL : %s\n", opt)
 	}
+	assert.Assert(t, !commands[0].Root, "Expected build command to run as user")
+	assert.Assert(t, commands[1].Root, "Expected install command to run as root")
+	assert.Equal(t, commands[1].Section, "installation")
+	contents, err := ExtractContents(doc)
+	assert.Assert(t, is.Nil(err))
+	assert.DeepEqual(t, contents.Programs, []string{"exiv2"})
+	assert.DeepEqual(t, contents.Libraries, []string{"libexiv2.so", "libxmp.a"})
+	assert.DeepEqual(t, contents.Directories, []string{"/usr/include/exiv2", "/usr/share/exiv2"})
 }
 
 // TestExtractApplication func takes no input and returns t *testing.T
@@ -661,6 +674,7 @@ rm -v /usr/share/doc/freetype-2.10.1/freetype-config.1</kbd>
 	assert.Assert(t, is.Nil(err))
 	assert.Equal(t, app.Name, "freetype", "Expected name to be freetype")
 	assert.Equal(t, app.Version, "2.10.1", "Expected version to be 2.10.1")
+	assert.Assert(t, !app.VersionGuessed, "Expected version to be parsed")
 	fmt.Printf("APPLICATION NAME : %s\n", app.Name)
 	fmt.Printf("APPLICATION VERSION : %s\n", app.Version)
 	fmt.Printf("APPLICATION DESCRIPTION : %s\n", app.Description)
@@ -910,13 +924,15 @@ mandb -c /opt/jdk/man</kbd>
 	app, aerr := ExtractApplication(doc)
 	assert.Assert(t, is.Nil(aerr))
 	assert.Equal(t, app.Name, "configuring-the-java-environment", "Expected name to be configurinng-the-java-environment")
-	assert.Equal(t, app.Version, "1.0.0", "Expected version to be 1.0.0")
+	assert.Equal(t, app.Version, "", "Expected version to be empty")
+	assert.Assert(t, app.VersionGuessed, "Expected version to be guessed")
 	fmt.Printf("APPLICATION NAME : %s\n", app.Name)
 	fmt.Printf("APPLICATION VERSION : %s\n", app.Version)
 	_, derr := ExtractDependencies(doc)
 	assert.Equal(t, "no dependencies found", derr.Error(), "error string not expected")
 	pkg, err := CreatePackageInformation([]byte(htmlPkg))
 	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, is.Contains(pkg.Diagnostics, "contents not found"))
 	yml, err := pkg.ToYAML()
 	assert.Assert(t, is.Nil(err))
 	fmt.Printf("%s\n", yml)
@@ -1105,3 +1121,41 @@ func TestCreatePackageInformation(t *testing.T) {
 	assert.Assert(t, is.Nil(err))
 	fmt.Printf("%s\n", yml)
 }
+
+// TestWriteStream func takes no input and returns t *testing.T
+func TestWriteStream(t *testing.T) {
+	pkgs := []*PackageInformation{
+		{Name: "tcl", Version: "8.6.9", Path: "general/tcl.html", Commands: []Command{{Cmd: "make", Index: 0}}},
+		{Name: "tk", Version: "8.6.9", Path: "general/tk.html"},
+	}
+	var buf bytes.Buffer
+	for _, pkgInfo := range pkgs {
+		assert.Assert(t, is.Nil(WriteStream(&buf, pkgInfo, true)))
+	}
+	names := make([]string, 0)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		pkgInfo := &PackageInformation{}
+		assert.Assert(t, is.Nil(json.Unmarshal(scanner.Bytes(), pkgInfo)))
+		names = append(names, pkgInfo.Name+" "+pkgInfo.Path)
+	}
+	assert.DeepEqual(t, names, []string{"tcl general/tcl.html", "tk general/tk.html"})
+
+	buf.Reset()
+	for _, pkgInfo := range pkgs {
+		assert.Assert(t, is.Nil(WriteStream(&buf, pkgInfo, false)))
+	}
+	assert.Assert(t, bytes.HasPrefix(buf.Bytes(), []byte("---\n")))
+	names = names[:0]
+	decoder := yaml.NewDecoder(&buf)
+	for {
+		pkgInfo := &PackageInformation{}
+		err := decoder.Decode(pkgInfo)
+		if err == io.EOF {
+			break
+		}
+		assert.Assert(t, is.Nil(err))
+		names = append(names, pkgInfo.Name+" "+pkgInfo.Path)
+	}
+	assert.DeepEqual(t, names, []string{"tcl general/tcl.html", "tk general/tk.html"})
+}
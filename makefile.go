@@ -0,0 +1,163 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// makefileTemplate is the GNU Makefile layout
+const makefileTemplate = `# Generated by cmdext for {{ join .Targets " " }}
+SOURCES ?= $(CURDIR)/sources
+BUILD ?= $(CURDIR)/build
+STAMPS ?= $(CURDIR)/stamps
+
+SHELL := /bin/bash
+.SHELLFLAGS := -e -c
+.ONESHELL:
+
+.PHONY: all
+all:{{ range .Targets }} {{ . }}{{ end }}
+{{ range .Sources }}
+$(SOURCES)/{{ .File }}:
+	mkdir -p $(SOURCES)
+	wget -O $@.part {{ .Archive }}
+{{ if .MD5Sum }}	echo '{{ .MD5Sum }}  $@.part' | md5sum -c -
+{{ end }}	mv $@.part $@
+{{ end }}{{ range $pkg := .Packages }}
+.PHONY: {{ $pkg.Name }}
+{{ $pkg.Name }}: $(STAMPS)/{{ $pkg.Name }}
+
+$(STAMPS)/{{ $pkg.Name }}:{{ range $pkg.Depends }} $(STAMPS)/{{ . }}{{ end }}{{ range $pkg.Files }} $(SOURCES)/{{ . }}{{ end }}
+	rm -rf $(BUILD)/{{ $pkg.Name }}
+	mkdir -p $(BUILD)/{{ $pkg.Name }}
+	cd $(BUILD)/{{ $pkg.Name }}
+{{ range $pkg.Files }}	ln -sf $(SOURCES)/{{ . }} .
+{{ end }}{{ if $pkg.Primary }}	tar -xf {{ $pkg.Primary }}
+	cd "{{ $pkg.SrcDir }}"
+{{ end }}{{ range $pkg.Commands }}{{ . }}
+{{ end }}	mkdir -p $(STAMPS)
+	touch $@
+{{ end }}
+.PHONY: clean
+clean:
+	rm -rf $(BUILD) $(STAMPS)
+`
+
+// makeSource struct for makesource
+type makeSource struct {
+	Archive string
+	File    string
+	MD5Sum  string
+}
+
+// makePackage struct for makepackage
+type makePackage struct {
+	Name     string
+	Depends  []string
+	Files    []string
+	Primary  string
+	SrcDir   string
+	Commands []string
+}
+
+// makefileData struct for makefiledata
+type makefileData struct {
+	Targets  []string
+	Sources  []makeSource
+	Packages []makePackage
+}
+
+// makeRecipe func takes cmd string input and returns string
+// Every line gets the recipe tab and $ is escaped from make.
+func makeRecipe(cmd string) string {
+	lines := strings.Split(strings.TrimSpace(cmd), "\n")
+	for i, line := range lines {
+		lines[i] = "\t" + strings.ReplaceAll(line, "$", "$$")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EmitMakefile func takes g *DependencyGraph, targets []string input and returns []byte, error
+// Each package is built by its stamp file target, which depends on the stamps
+// of the packages it needs and on its downloaded sources.
+func EmitMakefile(g *DependencyGraph, targets []string) ([]byte, error) {
+	order := g.Order(targets)
+	if len(order) == 0 {
+		return nil, fmt.Errorf("no known packages in %s", strings.Join(targets, ", "))
+	}
+	data := makefileData{}
+	for _, target := range targets {
+		if target = g.Key(target); target != "" {
+			data.Targets = append(data.Targets, target)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, name := range order {
+		pkgInfo := g.Packages[name]
+		pkg := makePackage{Name: name, Depends: g.Edges(name)}
+		for _, src := range emitterSources(pkgInfo) {
+			file := sourceFile(src.Archive)
+			pkg.Files = append(pkg.Files, file)
+			if !seen[file] {
+				seen[file] = true
+				data.Sources = append(data.Sources, makeSource{Archive: src.Archive, File: file, MD5Sum: src.MD5Sum})
+			}
+		}
+		if pkg.Primary = unpackedArchive(pkgInfo); pkg.Primary != "" {
+			word := sourceDirWord(pkgInfo, `"`+doubleQuote(pkg.Primary)+`"`)
+			pkg.SrcDir = strings.ReplaceAll(word, "$", "$$")
+		}
+		for _, cmd := range pkgInfo.Commands {
+			pkg.Commands = append(pkg.Commands, makeRecipe(cmd.Cmd))
+		}
+		data.Packages = append(data.Packages, pkg)
+	}
+	tmpl, err := ParseTemplate("Makefile", makefileTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return Render(tmpl, data)
+}
+
+// runMakefile func takes args []string input and returns error
+func runMakefile(args []string) error {
+	var (
+		output      string
+		overrides   string
+		recommended bool
+	)
+	flags := flag.NewFlagSet("makefile", flag.ExitOnError)
+	flags.StringVar(&output, "output", "", "Path to write the Makefile to instead of stdout")
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&recommended, "recommended", false, "Build recommended dependencies too")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s makefile [options] <book|catalog|packages> <target>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return fmt.Errorf("makefile requires a book and at least one target package")
+	}
+	pkgs, err := LoadPackages(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	content, err := EmitMakefile(NewDependencyGraph(pkgs, recommended), flags.Args()[1:])
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Printf("%s", content)
+		return nil
+	}
+	return ioutil.WriteFile(output, content, 0644)
+}
@@ -0,0 +1,81 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestEmitMakefile func takes no input and returns t *testing.T
+func TestEmitMakefile(t *testing.T) {
+	zlib := &PackageInformation{
+		Name:     "zlib",
+		Version:  "1.2.11",
+		Sources:  []Source{{Archive: "https://zlib.net/zlib-1.2.11.tar.xz", MD5Sum: "85adef240c5f370b308da8c938951a68"}},
+		Commands: []Command{{Cmd: "./configure --prefix=/usr &&\nmake", Index: 0}},
+	}
+	tcl := &PackageInformation{
+		Name:         "tcl",
+		Version:      "8.6.9",
+		Dependencies: Dependencies{Requires: []string{"zlib-1.2.11"}},
+		Sources: []Source{
+			{Archive: "https://downloads.sourceforge.net/tcl/tcl8.6.9-src.tar.gz", MD5Sum: "aa0a121d95a0e7b73a036f26028538d4"},
+			{Archive: "https://downloads.sourceforge.net/tcl/tcl8.6.9-html.tar.gz", MD5Sum: "243da67cca49b9bac0dc6c06fdb42896"},
+		},
+		Commands: []Command{{Cmd: "export SRCDIR=`pwd` &&\ncd unix &&\n./configure --prefix=/usr &&\nmake", Index: 0}},
+	}
+	graph := NewDependencyGraph([]*PackageInformation{tcl, zlib}, false)
+	content, err := EmitMakefile(graph, []string{"tcl-8.6.9"})
+	assert.Assert(t, is.Nil(err))
+	makefile := string(content)
+	fmt.Printf("%s\n", makefile)
+	assert.Assert(t, strings.Contains(makefile, "all: tcl\n"))
+	assert.Assert(t, strings.Contains(makefile, "$(SOURCES)/zlib-1.2.11.tar.xz:\n\tmkdir -p $(SOURCES)\n\twget -O $@.part https://zlib.net/zlib-1.2.11.tar.xz\n\techo '85adef240c5f370b308da8c938951a68  $@.part' | md5sum -c -\n"))
+	assert.Assert(t, strings.Contains(makefile, "$(STAMPS)/tcl: $(STAMPS)/zlib $(SOURCES)/tcl8.6.9-src.tar.gz $(SOURCES)/tcl8.6.9-html.tar.gz\n"))
+	assert.Assert(t, strings.Contains(makefile, "\ttar -xf tcl8.6.9-src.tar.gz\n\tcd \"$$(tar -tf \"tcl8.6.9-src.tar.gz\" | head -n 1 | sed -e 's@^\\./@@' -e 's@/.*@@')\"\n"))
+	assert.Assert(t, strings.Contains(makefile, "\texport SRCDIR=`pwd` &&\n"))
+	assert.Assert(t, strings.Index(makefile, "$(STAMPS)/zlib:") < strings.Index(makefile, "$(STAMPS)/tcl:"))
+
+	_, err = EmitMakefile(graph, []string{"unknown"})
+	assert.ErrorContains(t, err, "no known packages")
+}
+
+// TestMakefileRun func takes no input and returns t *testing.T
+// The generated Makefile is run with harmless commands when make is installed.
+func TestMakefileRun(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make is not installed")
+	}
+	pkgs := []*PackageInformation{
+		{Name: "a", Commands: []Command{{Cmd: "echo \"$PWD\" > a.txt", Index: 0}}},
+		{Name: "b", Dependencies: Dependencies{Requires: []string{"a-1.0"}}, Commands: []Command{{Cmd: "test -s ../a/a.txt &&\ncp ../a/a.txt b.txt", Index: 0}}},
+	}
+	content, err := EmitMakefile(NewDependencyGraph(pkgs, false), []string{"b"})
+	assert.Assert(t, is.Nil(err))
+	dir, err := ioutil.TempDir("", "makefile")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(path.Join(dir, "Makefile"), content, 0644)
+	assert.Assert(t, is.Nil(err))
+	for i := 0; i < 2; i++ {
+		cmd := exec.Command("make", "-j2")
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.Assert(t, is.Nil(err), string(out))
+	}
+	b, err := ioutil.ReadFile(path.Join(dir, "build", "b", "b.txt"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, string(b), path.Join(dir, "build", "a")+"\n")
+	_, err = os.Stat(path.Join(dir, "stamps", "b"))
+	assert.Assert(t, is.Nil(err))
+}
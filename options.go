@@ -0,0 +1,190 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"strings"
+)
+
+// BuildOption struct for buildoption
+// A switch passed to configure, cmake, meson or qmake. Command is the index
+// of the Command it is passed in. Operator is set for qmake's +=, -=, *= and
+// ~= assignments, e.g. Flag CONFIG, Operator += and Value release.
+type BuildOption struct {
+	Tool        string `json:"tool" yaml:"tool"`
+	Flag        string `json:"flag" yaml:"flag"`
+	Operator    string `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Value       string `json:"value,omitempty" yaml:"value,omitempty"`
+	Command     int    `json:"command" yaml:"command"`
+	Explanation string `json:"explanation,omitempty" yaml:"explanation,omitempty"`
+}
+
+// optionTool struct for optiontool
+// Separate lists the switches that take their value from the next argument.
+// With Assign, NAME=value arguments are options as well.
+type optionTool struct {
+	Name     string
+	Separate []string
+	Assign   bool
+}
+
+// optionTools are the configure steps options are extracted from, by program name
+var optionTools = map[string]optionTool{
+	"configure": {Name: "configure", Assign: true},
+	"cmake":     {Name: "cmake", Separate: []string{"-D", "-G", "-S", "-B", "-C", "-T", "-A", "-U"}},
+	"meson":     {Name: "meson", Separate: []string{"-D", "--prefix", "--libdir", "--bindir", "--sysconfdir", "--localstatedir", "--buildtype", "--backend", "--default-library", "--wrap-mode", "--cross-file", "--native-file"}},
+	"qmake":     {Name: "qmake", Separate: []string{"-spec", "-xspec", "-o", "-t", "-tp"}, Assign: true},
+}
+
+// stepTool func takes step Step input and returns optionTool, []string, bool
+// The tool a step configures with and the arguments holding its options.
+// cmake --build, meson compile and the like are not configure steps.
+func stepTool(step Step) (optionTool, []string, bool) {
+	name := step.Name()
+	if strings.HasPrefix(name, "qmake") {
+		name = "qmake"
+	}
+	tool, ok := optionTools[name]
+	if !ok || len(step.Argv) < 2 {
+		return tool, nil, false
+	}
+	args := step.Argv[1:]
+	switch name {
+	case "cmake":
+		switch args[0] {
+		case "--build", "--install", "--open", "-E", "-P":
+			return tool, nil, false
+		}
+	case "meson":
+		switch {
+		case args[0] == "setup" || args[0] == "configure":
+			args = args[1:]
+		case !strings.HasPrefix(args[0], "-"):
+			return tool, nil, false
+		}
+	}
+	return tool, args, true
+}
+
+// separate func takes tool optionTool, arg string input and returns bool
+func separate(tool optionTool, arg string) bool {
+	for _, s := range tool.Separate {
+		if s == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOption func takes arg string input and returns string, string
+func splitOption(arg string) (string, string) {
+	if i := strings.Index(arg, "="); i > 0 {
+		return arg[:i], arg[i+1:]
+	}
+	return arg, ""
+}
+
+// splitAssignment func takes arg string input and returns string, string, string
+// NAME=value, and qmake's NAME+=value and the like, split into the name, the
+// operator when it is not a plain = and the value.
+func splitAssignment(arg string) (string, string, string) {
+	flag, value := splitOption(arg)
+	name := strings.TrimRight(flag, "+-*~")
+	if name == flag || name == "" {
+		return flag, "", value
+	}
+	return name, flag[len(name):] + "=", value
+}
+
+// isAssignment func takes arg string input and returns bool
+// NAME=value and qmake's NAME+=value and NAME-=value.
+func isAssignment(arg string) bool {
+	flag, _, _ := splitAssignment(arg)
+	return strings.Contains(arg, "=") && assignmentWord.MatchString(flag+"=")
+}
+
+// term func takes no input and returns string
+// The option as written on the command line.
+func (o BuildOption) term() string {
+	switch {
+	case o.Operator != "":
+		return o.Flag + o.Operator + o.Value
+	case o.Value != "":
+		return o.Flag + "=" + o.Value
+	}
+	return o.Flag
+}
+
+// stepOptions func takes step Step input and returns []BuildOption
+func stepOptions(step Step) []BuildOption {
+	tool, args, ok := stepTool(step)
+	if !ok {
+		return nil
+	}
+	options := make([]BuildOption, 0)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		option := BuildOption{Tool: tool.Name, Command: step.Command}
+		switch {
+		case separate(tool, arg) && i+1 < len(args):
+			i++
+			option.Flag, option.Value = arg, args[i]
+			if arg == "-D" {
+				option.Flag, option.Value = splitOption("-D" + args[i])
+			}
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			option.Flag, option.Value = splitOption(arg)
+		case strings.HasPrefix(arg, "$"):
+			option.Flag = arg
+		case tool.Assign && isAssignment(arg):
+			option.Flag, option.Operator, option.Value = splitAssignment(arg)
+		default:
+			continue
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// optionExplanations func takes explanations []Explanation input and returns map[string]string
+// The map holds the text of each term, and of the term's flag without its
+// value, e.g. -DEXIV2_ENABLE_VIDEO for -DEXIV2_ENABLE_VIDEO=yes.
+func optionExplanations(explanations []Explanation) map[string]string {
+	texts := make(map[string]string)
+	for _, e := range explanations {
+		if _, ok := texts[e.Term]; !ok {
+			texts[e.Term] = e.Text
+		}
+		if flag, _, _ := splitAssignment(e.Term); flag != e.Term {
+			if _, ok := texts[flag]; !ok {
+				texts[flag] = e.Text
+			}
+		}
+	}
+	return texts
+}
+
+// ExtractOptions func takes cmds []Command, explanations []Explanation input and returns []BuildOption
+// Options are taken from the configure, cmake, meson and qmake steps of the
+// commands, with the Command Explanations text of their switch.
+func ExtractOptions(cmds []Command, explanations []Explanation) []BuildOption {
+	texts := optionExplanations(explanations)
+	options := make([]BuildOption, 0)
+	for _, cmd := range cmds {
+		steps, _, err := ParseSteps(cmd, ".")
+		if err != nil {
+			continue
+		}
+		for _, step := range steps {
+			for _, option := range stepOptions(step) {
+				option.Explanation = texts[option.term()]
+				if option.Explanation == "" {
+					option.Explanation = texts[option.Flag]
+				}
+				options = append(options, option)
+			}
+		}
+	}
+	return options
+}
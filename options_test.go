@@ -0,0 +1,88 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// optionsPage is a page with configure steps and their explanations
+const optionsPage = `<html><body>
+<div class="installation">
+<pre class="userinput"><kbd class="command">./configure --prefix=/usr    \
+            --disable-static \
+            --with-python=python3 \
+            $XORG_CONFIG &amp;&amp;
+make</kbd></pre>
+<pre class="userinput"><kbd class="command">mkdir build &amp;&amp;
+cd    build &amp;&amp;
+
+cmake -DCMAKE_INSTALL_PREFIX=/usr \
+      -D EXIV2_ENABLE_VIDEO=yes   \
+      -G "Unix Makefiles" .. &amp;&amp;
+cmake --build .</kbd></pre>
+<pre class="userinput"><kbd class="command">meson setup --prefix /usr --buildtype=release -Dgtk_doc=false .. &amp;&amp;
+meson compile</kbd></pre>
+<pre class="userinput"><kbd class="command">qmake-qt5 CONFIG+=release PREFIX=/usr foo.pro</kbd></pre>
+</div>
+<div class="commands">
+<h2 class="sect2">Command Explanations</h2>
+<p><em class="parameter"><code>--disable-static</code></em>: This switch
+prevents installation of static versions of the libraries.</p>
+<p><em class="parameter"><code>--with-python=python3</code></em>: This switch
+builds the Python 3 bindings.</p>
+<p><em class="parameter"><code>-DEXIV2_ENABLE_VIDEO=yes</code></em>: This
+switch enables managing video metadata.</p>
+<p><em class="parameter"><code>CONFIG+=release</code></em>: This builds
+without debugging information.</p>
+<p><span class="command"><strong>make check</strong></span> runs the tests.</p>
+</div>
+</body></html>`
+
+// TestExtractOptions func takes no input and returns t *testing.T
+func TestExtractOptions(t *testing.T) {
+	doc, err := ReadDoc([]byte(optionsPage))
+	assert.Assert(t, is.Nil(err))
+	cmds, err := ExtractCommands(doc)
+	assert.Assert(t, is.Nil(err))
+	options := ExtractOptions(cmds, ExtractExplanations(doc, cmds))
+	assert.DeepEqual(t, options, []BuildOption{
+		{Tool: "configure", Flag: "--prefix", Value: "/usr", Command: 0},
+		{Tool: "configure", Flag: "--disable-static", Command: 0,
+			Explanation: "This switch prevents installation of static versions of the libraries."},
+		{Tool: "configure", Flag: "--with-python", Value: "python3", Command: 0,
+			Explanation: "This switch builds the Python 3 bindings."},
+		{Tool: "configure", Flag: "$XORG_CONFIG", Command: 0},
+		{Tool: "cmake", Flag: "-DCMAKE_INSTALL_PREFIX", Value: "/usr", Command: 1},
+		{Tool: "cmake", Flag: "-DEXIV2_ENABLE_VIDEO", Value: "yes", Command: 1,
+			Explanation: "This switch enables managing video metadata."},
+		{Tool: "cmake", Flag: "-G", Value: "Unix Makefiles", Command: 1},
+		{Tool: "meson", Flag: "--prefix", Value: "/usr", Command: 2},
+		{Tool: "meson", Flag: "--buildtype", Value: "release", Command: 2},
+		{Tool: "meson", Flag: "-Dgtk_doc", Value: "false", Command: 2},
+		{Tool: "qmake", Flag: "CONFIG", Operator: "+=", Value: "release", Command: 3,
+			Explanation: "This builds without debugging information."},
+		{Tool: "qmake", Flag: "PREFIX", Value: "/usr", Command: 3},
+	})
+}
+
+// TestStepOptions func takes no input and returns t *testing.T
+func TestStepOptions(t *testing.T) {
+	assert.Equal(t, len(stepOptions(Step{Argv: []string{"make", "-j4"}})), 0)
+	assert.Equal(t, len(stepOptions(Step{Argv: []string{"meson", "install"}})), 0)
+	assert.Equal(t, len(stepOptions(Step{Argv: []string{"cmake", "--install", "build"}})), 0)
+	options := stepOptions(Step{Argv: []string{"../configure", "CC=gcc", "--enable-shared"}, Command: 2})
+	assert.DeepEqual(t, options, []BuildOption{
+		{Tool: "configure", Flag: "CC", Value: "gcc", Command: 2},
+		{Tool: "configure", Flag: "--enable-shared", Command: 2},
+	})
+	options = stepOptions(Step{Argv: []string{"qmake", "CONFIG-=debug", "QMAKE_CXXFLAGS*=-O2", "foo.pro"}, Command: 1})
+	assert.DeepEqual(t, options, []BuildOption{
+		{Tool: "qmake", Flag: "CONFIG", Operator: "-=", Value: "debug", Command: 1},
+		{Tool: "qmake", Flag: "QMAKE_CXXFLAGS", Operator: "*=", Value: "-O2", Command: 1},
+	})
+}
@@ -0,0 +1,249 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Override struct for override
+type Override struct {
+	Name         string         `json:"name" yaml:"name"`
+	Version      string         `json:"version,omitempty" yaml:"version,omitempty"`
+	Commands     []CommandPatch `json:"commands,omitempty" yaml:"commands,omitempty"`
+	Dependencies Dependencies   `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Sources      []Source       `json:"sources,omitempty" yaml:"sources,omitempty"`
+	SourceDir    string         `json:"source_dir,omitempty" yaml:"source_dir,omitempty"`
+	File         string         `json:"-" yaml:"-"`
+}
+
+// CommandPatch struct for commandpatch
+//
+// Action is one of replace, insert or delete. The target command is selected
+// by Index (the index assigned at extraction time) or by Match (a substring
+// of the command text). Insert places Cmd at Index, or after the command
+// selected by Match.
+type CommandPatch struct {
+	Action string `json:"action" yaml:"action"`
+	Index  *int   `json:"index,omitempty" yaml:"index,omitempty"`
+	Match  string `json:"match,omitempty" yaml:"match,omitempty"`
+	Cmd    string `json:"cmd,omitempty" yaml:"cmd,omitempty"`
+}
+
+// overrideExtensions are the file extensions searched for in an overrides directory
+var overrideExtensions = []string{".yaml", ".yml", ".json"}
+
+// ReadOverride func takes filepath string input and returns *Override, error
+func ReadOverride(filepath string) (*Override, error) {
+	b, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	override := &Override{}
+	if strings.HasSuffix(filepath, ".json") {
+		err = json.Unmarshal(b, override)
+	} else {
+		err = yaml.Unmarshal(b, override)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read override %s : %v", filepath, err)
+	}
+	override.File = filepath
+	return override, nil
+}
+
+// FindOverride func takes dir, name string input and returns *Override, error
+// A nil Override and nil error are returned when no override exists for name.
+func FindOverride(dir, name string) (*Override, error) {
+	if dir == "" || name == "" {
+		return nil, nil
+	}
+	for _, ext := range overrideExtensions {
+		filepath := path.Join(dir, name+ext)
+		if _, err := os.Stat(filepath); err != nil {
+			continue
+		}
+		override, err := ReadOverride(filepath)
+		if err != nil {
+			return nil, err
+		}
+		if override.Name == "" {
+			override.Name = name
+		}
+		return override, nil
+	}
+	return nil, nil
+}
+
+// findCommand func takes cmds []Command, p CommandPatch input and returns int
+// The returned position is the slice position of the target command or -1.
+func findCommand(cmds []Command, p CommandPatch) int {
+	for i, cmd := range cmds {
+		if p.Index != nil {
+			if cmd.Index == *p.Index {
+				return i
+			}
+			continue
+		}
+		if p.Match != "" && strings.Contains(cmd.Cmd, p.Match) {
+			return i
+		}
+	}
+	return -1
+}
+
+// describe func takes no input and returns string
+func (p CommandPatch) describe() string {
+	if p.Index != nil {
+		return fmt.Sprintf("%s command %d", p.Action, *p.Index)
+	}
+	return fmt.Sprintf("%s command matching %q", p.Action, p.Match)
+}
+
+// containsString func takes list []string, item string input and returns bool
+func containsString(list []string, item string) bool {
+	for _, l := range list {
+		if l == item {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique func takes list []string, items []string input and returns []string, []string
+// The items not already in list are appended and returned as added.
+func appendUnique(list []string, items ...string) ([]string, []string) {
+	var added []string
+	for _, item := range items {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" && !containsString(list, item) && !containsString(added, item) {
+			added = append(added, item)
+		}
+	}
+	if len(added) == 0 {
+		return list, nil
+	}
+	return append(append([]string(nil), list...), added...), added
+}
+
+// sameSources func takes a, b []Source input and returns bool
+func sameSources(a, b []Source) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply func takes pkgInfo *PackageInformation input and returns error
+// The override is applied to a copy of the package, pkgInfo is only changed
+// when every change applies, and the fields derived from the commands are
+// worked out again afterwards. Each change made is recorded in
+// pkgInfo.Overrides. An override for another version is skipped and noted
+// in pkgInfo.Diagnostics.
+func (o *Override) Apply(pkgInfo *PackageInformation) error {
+	source := o.File
+	if source == "" {
+		source = o.Name
+	}
+	if o.Version != "" && o.Version != pkgInfo.Version {
+		pkgInfo.Diagnostics = append(pkgInfo.Diagnostics,
+			fmt.Sprintf("%s: override is for version %s not %s, skipped", source, o.Version, pkgInfo.Version))
+		return nil
+	}
+	result := *pkgInfo
+	result.Overrides = append([]string(nil), pkgInfo.Overrides...)
+	record := func(msg string) {
+		result.Overrides = append(result.Overrides, source+": "+msg)
+	}
+	cmds := append([]Command(nil), pkgInfo.Commands...)
+	for _, p := range o.Commands {
+		if p.Index == nil && p.Match == "" {
+			return fmt.Errorf("%s : %s requires an index or match", source, p.Action)
+		}
+		pos := findCommand(cmds, p)
+		if pos < 0 {
+			return fmt.Errorf("%s : %s not found", source, p.describe())
+		}
+		switch p.Action {
+		case "replace":
+			if cmds[pos].Cmd == p.Cmd {
+				continue
+			}
+			cmds[pos].Cmd = p.Cmd
+		case "delete":
+			cmds = append(cmds[:pos], cmds[pos+1:]...)
+		case "insert":
+			if p.Index == nil {
+				pos++
+			}
+			cmd := Command{Cmd: p.Cmd, Index: -1}
+			cmds = append(cmds[:pos], append([]Command{cmd}, cmds[pos:]...)...)
+		default:
+			return fmt.Errorf("%s : unknown action %q", source, p.Action)
+		}
+		record(p.describe())
+	}
+	for i := range cmds {
+		cmds[i].Index = i
+	}
+	result.Commands = cmds
+
+	var added []string
+	deps := o.Dependencies
+	if result.Dependencies.Requires, added = appendUnique(pkgInfo.Dependencies.Requires, deps.Requires...); len(added) > 0 {
+		record("add requires " + strings.Join(added, ", "))
+	}
+	if result.Dependencies.Recommended, added = appendUnique(pkgInfo.Dependencies.Recommended, deps.Recommended...); len(added) > 0 {
+		record("add recommended " + strings.Join(added, ", "))
+	}
+	if result.Dependencies.Optional, added = appendUnique(pkgInfo.Dependencies.Optional, deps.Optional...); len(added) > 0 {
+		record("add optional " + strings.Join(added, ", "))
+	}
+
+	if len(o.Sources) > 0 && !sameSources(o.Sources, pkgInfo.Sources) {
+		result.Sources = o.Sources
+		for _, src := range o.Sources {
+			record("pin source " + src.Archive)
+		}
+	}
+	if dir := path.Clean(o.SourceDir); o.SourceDir != "" && (path.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../")) {
+		return fmt.Errorf("%s : source dir %q is not below the build directory", source, o.SourceDir)
+	}
+	if o.SourceDir != "" && o.SourceDir != pkgInfo.SourceDir {
+		result.SourceDir = o.SourceDir
+		record("set source dir " + o.SourceDir)
+	}
+	*pkgInfo = result
+	deriveFields(pkgInfo)
+	return nil
+}
+
+// ApplyOverrides func takes pkgInfo *PackageInformation, dir string input and returns error
+// A package that already records overrides, such as generated YAML read
+// back, is left as it is so its override is not applied twice.
+func ApplyOverrides(pkgInfo *PackageInformation, dir string) error {
+	if len(pkgInfo.Overrides) > 0 {
+		return nil
+	}
+	override, err := FindOverride(dir, pkgInfo.Name)
+	if err != nil {
+		return err
+	}
+	if override == nil {
+		return nil
+	}
+	return override.Apply(pkgInfo)
+}
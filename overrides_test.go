@@ -0,0 +1,169 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestApplyOverrides func takes no input and returns t *testing.T
+func TestApplyOverrides(t *testing.T) {
+	override := `name: tcl
+commands:
+  - action: replace
+    index: 0
+    cmd: ./configure --prefix=/opt/tcl
+  - action: delete
+    match: make doc
+  - action: insert
+    match: make install
+    cmd: ln -sv tclsh8.6 /opt/tcl/bin/tclsh
+dependencies:
+  requires:
+    - zlib
+sources:
+  - archive: https://example.com/tcl8.6.9-src.tar.gz
+    md5sum: aa9a9d39b4a2ef4b3c1d76bd1e0a5f6e
+`
+	dir, err := ioutil.TempDir("", "overrides")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(path.Join(dir, "tcl.yaml"), []byte(override), 0644)
+	assert.Assert(t, is.Nil(err))
+
+	pkgInfo := &PackageInformation{
+		Name:    "tcl",
+		Version: "8.6.9",
+		Commands: []Command{
+			{Cmd: "./configure --prefix=/usr", Index: 0},
+			{Cmd: "make", Index: 1},
+			{Cmd: "make doc", Index: 2},
+			{Cmd: "make install", Index: 3},
+		},
+		Dependencies: Dependencies{Requires: []string{"zlib"}},
+	}
+	err = ApplyOverrides(pkgInfo, dir)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(pkgInfo.Commands), 4)
+	assert.Equal(t, pkgInfo.Commands[0].Cmd, "./configure --prefix=/opt/tcl")
+	assert.Equal(t, pkgInfo.Commands[2].Cmd, "make install")
+	assert.Equal(t, pkgInfo.Commands[3].Cmd, "ln -sv tclsh8.6 /opt/tcl/bin/tclsh")
+	assert.Equal(t, pkgInfo.Commands[3].Index, 3)
+	assert.DeepEqual(t, pkgInfo.Dependencies.Requires, []string{"zlib"})
+	assert.Equal(t, len(pkgInfo.Sources), 1)
+	assert.Equal(t, len(pkgInfo.Overrides), 4)
+	for _, record := range pkgInfo.Overrides {
+		assert.Assert(t, !strings.Contains(record, "add requires"))
+	}
+
+	missing := &PackageInformation{Name: "tk"}
+	err = ApplyOverrides(missing, dir)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(missing.Overrides), 0)
+
+	bad := &Override{Name: "tcl", Commands: []CommandPatch{
+		{Action: "replace", Match: "make", Cmd: "make -j1"},
+		{Action: "delete", Match: "nope"},
+	}}
+	unchanged := &PackageInformation{Name: "tcl", Commands: []Command{{Cmd: "make"}}}
+	err = bad.Apply(unchanged)
+	assert.ErrorContains(t, err, "not found")
+	assert.Equal(t, unchanged.Commands[0].Cmd, "make")
+	assert.Equal(t, len(unchanged.Overrides), 0)
+
+	other := &Override{Name: "tcl", Version: "8.6.10", Dependencies: Dependencies{Requires: []string{"zlib"}}}
+	older := &PackageInformation{Name: "tcl", Version: "8.6.9"}
+	err = other.Apply(older)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(older.Overrides), 0)
+	assert.Equal(t, len(older.Dependencies.Requires), 0)
+	assert.DeepEqual(t, older.Diagnostics, []string{"tcl: override is for version 8.6.10 not 8.6.9, skipped"})
+
+	srcdir := &Override{Name: "tcl", SourceDir: "tcl8.6.9"}
+	err = srcdir.Apply(older)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, older.SourceDir, "tcl8.6.9")
+	assert.DeepEqual(t, older.Overrides, []string{"tcl: set source dir tcl8.6.9"})
+	srcdir.SourceDir = "../tcl8.6.9"
+	err = srcdir.Apply(older)
+	assert.ErrorContains(t, err, "not below the build directory")
+}
+
+// TestApplyOverridesDerived func takes no input and returns t *testing.T
+func TestApplyOverridesDerived(t *testing.T) {
+	pkgInfo := &PackageInformation{
+		Name:    "foo",
+		Version: "1.0",
+		Commands: []Command{
+			{Cmd: "groupadd -g 18 foo", Index: 0, Root: true},
+			{Cmd: "patch -Np1 -i ../foo-1.0-fixes-1.patch", Index: 1},
+			{Cmd: "./configure --prefix=/usr --disable-static &&\nmake", Index: 2},
+			{Cmd: "cat > /etc/foo.conf << \"EOF\"\nfoo=1\nEOF", Index: 3, Root: true},
+		},
+		Explanations: []Explanation{{Term: "--disable-static", Text: "This switch prevents installation of static versions of the libraries."}},
+		ConfigFiles:  []ConfigFile{{Path: "~/.foorc", Command: -1}},
+		Sources:      []Source{{Archive: "http://example.com/foo-1.0-fixes-1.patch"}},
+	}
+	deriveFields(pkgInfo)
+	assert.Equal(t, len(pkgInfo.Accounts), 1)
+	assert.DeepEqual(t, pkgInfo.Explanations[0].Commands, []int{2})
+	assert.Equal(t, pkgInfo.Fixups[0].Command, 1)
+
+	override := &Override{Name: "foo", Commands: []CommandPatch{
+		{Action: "delete", Match: "groupadd"},
+		{Action: "replace", Match: "configure", Cmd: "./configure --prefix=/opt/foo --disable-static &&\nmake"},
+	}}
+	err := override.Apply(pkgInfo)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(pkgInfo.Commands), 3)
+	assert.Equal(t, len(pkgInfo.Accounts), 0)
+	assert.DeepEqual(t, pkgInfo.Explanations[0].Commands, []int{1})
+	assert.Equal(t, pkgInfo.Fixups[0].Command, 0)
+	assert.Equal(t, pkgInfo.Fixups[0].Source, "http://example.com/foo-1.0-fixes-1.patch")
+	assert.Assert(t, pkgInfo.Commands[0].Fixup)
+	assert.Assert(t, !pkgInfo.Commands[1].Fixup)
+	assert.Equal(t, pkgInfo.Options[0].Flag, "--prefix")
+	assert.Equal(t, pkgInfo.Options[0].Value, "/opt/foo")
+	assert.Equal(t, pkgInfo.Options[1].Command, 1)
+	assert.Equal(t, pkgInfo.Options[1].Explanation, "This switch prevents installation of static versions of the libraries.")
+	assert.DeepEqual(t, pkgInfo.ConfigFiles, []ConfigFile{
+		{Path: "/etc/foo.conf", Content: "foo=1\n", Command: 2},
+		{Path: "~/.foorc", Command: -1},
+	})
+}
+
+// TestLoadPackagesOverrides func takes no input and returns t *testing.T
+func TestLoadPackagesOverrides(t *testing.T) {
+	overrides, err := ioutil.TempDir("", "overrides")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(overrides)
+	err = ioutil.WriteFile(path.Join(overrides, "tcl.yaml"), []byte("name: tcl\ncommands:\n  - action: delete\n    match: make doc\n"), 0644)
+	assert.Assert(t, is.Nil(err))
+	dir := writePackages(t,
+		&PackageInformation{Name: "tcl", Version: "8.6.9", Commands: []Command{{Cmd: "make", Index: 0}, {Cmd: "make doc", Index: 1}}},
+		&PackageInformation{Name: "tk", Version: "8.6.9", Commands: []Command{{Cmd: "make doc", Index: 0}}},
+	)
+	defer os.RemoveAll(dir)
+
+	pkgs, err := LoadPackages(dir, overrides)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(pkgs[0].Commands), 1)
+	assert.Equal(t, len(pkgs[0].Overrides), 1)
+	assert.Equal(t, len(pkgs[1].Commands), 1)
+
+	pkgInfo, err := ReadPackageInformation(path.Join(dir, "tcl-8.6.9.yaml"), overrides)
+	assert.Assert(t, is.Nil(err))
+	assert.DeepEqual(t, pkgInfo.Commands, pkgs[0].Commands)
+
+	err = ApplyOverrides(pkgInfo, overrides)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(pkgInfo.Overrides), 1)
+}
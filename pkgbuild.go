@@ -0,0 +1,135 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// pkgbuildTemplate is the Arch Linux PKGBUILD layout
+const pkgbuildTemplate = `# Generated by cmdext from {{ .Name }}-{{ .Version }}
+pkgname={{ .PkgName }}
+pkgver={{ .PkgVer }}
+pkgrel=1
+pkgdesc={{ shellquote .Summary }}
+arch=('x86_64')
+url={{ shellquote .URL }}
+license=('unknown')
+depends=({{ range $i, $d := .Depends }}{{ if $i }} {{ end }}{{ shellquote $d }}{{ end }})
+optdepends=({{ range $i, $d := .OptDepends }}{{ if $i }}
+            {{ end }}{{ shellquote $d }}{{ end }})
+source=({{ range $i, $s := .Sources }}{{ if $i }}
+        {{ end }}{{ shellquote $s.Archive }}{{ end }})
+md5sums=({{ range $i, $s := .Sources }}{{ if $i }}
+         {{ end }}{{ if $s.MD5Sum }}{{ shellquote $s.MD5Sum }}{{ else }}'SKIP'{{ end }}{{ end }})
+
+# the directory the source unpacks to
+_srcdir() {
+  {{ .SrcDirCmd }}
+}
+{{ range $stage := .Stages }}
+{{ $stage.Func }}() {
+  cd "$srcdir/$(_srcdir)"
+{{ range $stage.Commands }}
+{{ . }}
+{{ end }}}
+{{ end }}`
+
+// pkgbuildStage struct for pkgbuildstage
+type pkgbuildStage struct {
+	Func     string
+	Commands []string
+}
+
+// pkgbuildData struct for pkgbuilddata
+type pkgbuildData struct {
+	*PackageInformation
+	PkgName    string
+	PkgVer     string
+	Summary    string
+	URL        string
+	SrcDirCmd  string
+	Depends    []string
+	OptDepends []string
+	Sources    []Source
+	Stages     []pkgbuildStage
+}
+
+// pkgbuildFuncs maps build stages to PKGBUILD functions
+var pkgbuildFuncs = map[string]string{
+	StagePrepare: "prepare",
+	StageBuild:   "build",
+	StageCheck:   "check",
+	StageInstall: "package",
+}
+
+// homepage func takes archive string input and returns string
+func homepage(archive string) string {
+	u, err := url.Parse(archive)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + "/"
+}
+
+// pkgbuildSourceDir is the source directory as shell text in PKGBUILD functions
+const pkgbuildSourceDir = `$srcdir/$(_srcdir)`
+
+// EmitPKGBUILD func takes pkgInfo *PackageInformation, opts EmitOptions input and returns []byte, error
+// Install commands are rewritten to install into $pkgdir. Each function
+// starts in the unpacked source and changes to the directory its first
+// command runs in.
+func EmitPKGBUILD(pkgInfo *PackageInformation, opts EmitOptions) ([]byte, error) {
+	primary := primarySource(pkgInfo)
+	archive := ""
+	if file := unpackedArchive(pkgInfo); file != "" {
+		archive = `"$srcdir/` + doubleQuote(file) + `"`
+	}
+	data := pkgbuildData{
+		PackageInformation: pkgInfo,
+		PkgName:            strings.ToLower(pkgInfo.Name),
+		PkgVer:             strings.ReplaceAll(pkgInfo.Version, "-", "_"),
+		Summary:            summary(pkgInfo.Description),
+		URL:                homepage(primary.Archive),
+		SrcDirCmd:          sourceDirCommand(pkgInfo, archive),
+		Depends:            dependencyNames(pkgInfo.Dependencies.Requires),
+		Sources:            emitterSources(pkgInfo),
+	}
+	for _, dep := range dependencyNames(pkgInfo.Dependencies.Recommended) {
+		data.OptDepends = append(data.OptDepends, dep+": recommended")
+	}
+	for _, dep := range dependencyNames(pkgInfo.Dependencies.Optional) {
+		data.OptDepends = append(data.OptDepends, dep+": optional")
+	}
+	stages := stagedStages(pkgInfo.Commands, opts.StagingDir(`"$pkgdir"`))
+	dirs := commandDirs(pkgInfo.Commands)
+	for _, stage := range Stages {
+		cmds := stages[stage]
+		if len(cmds) == 0 && stage != StageInstall {
+			continue
+		}
+		s := pkgbuildStage{Func: pkgbuildFuncs[stage]}
+		s.Commands, _ = stageTexts(cmds, dirs, pkgbuildSourceDir, ".")
+		if len(s.Commands) == 0 {
+			s.Commands = []string{":"}
+		}
+		data.Stages = append(data.Stages, s)
+	}
+	tmpl, err := ParseTemplate("PKGBUILD", pkgbuildTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return Render(tmpl, data)
+}
+
+func init() {
+	RegisterEmitter(Emitter{
+		Name: "pkgbuild",
+		Emit: singleFile(func(pkgInfo *PackageInformation) string {
+			return path.Join(pkgInfo.Name, "PKGBUILD")
+		}, EmitPKGBUILD),
+	})
+}
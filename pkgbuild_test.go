@@ -0,0 +1,44 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestEmitPKGBUILD func takes no input and returns t *testing.T
+func TestEmitPKGBUILD(t *testing.T) {
+	content, err := EmitPKGBUILD(tclPackage(), EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	pkgbuild := string(content)
+	fmt.Printf("%s\n", pkgbuild)
+	assert.Assert(t, strings.Contains(pkgbuild, "pkgname=tcl\npkgver=8.6.9\n"))
+	assert.Assert(t, strings.Contains(pkgbuild, "pkgdesc='The Tcl package contains the Tool Command Language, a robust general-purpose scripting language'"))
+	assert.Assert(t, strings.Contains(pkgbuild, "depends=('zlib')"))
+	assert.Assert(t, strings.Contains(pkgbuild, "optdepends=('tk: recommended'\n            'doxygen: optional')"))
+	assert.Assert(t, strings.Contains(pkgbuild, "md5sums=('aa0a121d95a0e7b73a036f26028538d4'\n         '243da67cca49b9bac0dc6c06fdb42896')"))
+	assert.Assert(t, strings.Contains(pkgbuild, "_srcdir() {\n  tar -tf \"$srcdir/tcl8.6.9-src.tar.gz\" | head -n 1 | sed -e 's@^\\./@@' -e 's@/.*@@'\n}\n"))
+	assert.Assert(t, strings.Contains(pkgbuild, "prepare() {\n  cd \"$srcdir/$(_srcdir)\"\n\ntar -xf ../tcl8.6.9-html.tar.gz"))
+	assert.Assert(t, strings.Contains(pkgbuild, "check() {\n  cd \"$srcdir/$(_srcdir)\"\n\ncd \"$srcdir/$(_srcdir)/unix\"\n\nmake test\n}"))
+	assert.Assert(t, strings.Contains(pkgbuild, "cd \"$srcdir/$(_srcdir)/unix\"\n\nmake DESTDIR=\"$pkgdir\" install &&\nmake DESTDIR=\"$pkgdir\" install-private-headers"))
+	e, err := GetEmitter("pkgbuild")
+	assert.Assert(t, is.Nil(err))
+	files, err := e.Emit(tclPackage(), EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(files), 1)
+	assert.Equal(t, files[0].Path, "tcl/PKGBUILD")
+	_, err = GetEmitter("nope")
+	assert.ErrorContains(t, err, "unknown emitter")
+
+	pkgInfo := tclPackage()
+	pkgInfo.SourceDir = "tcl8.6.9"
+	content, err = EmitPKGBUILD(pkgInfo, EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.Contains(string(content), "_srcdir() {\n  echo 'tcl8.6.9'\n}\n"))
+}
@@ -0,0 +1,181 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const (
+	// versionPlaceholder stands for the package version in templated commands and sources
+	versionPlaceholder = "@VERSION@"
+	// majorMinorPlaceholder stands for the major.minor of the package version
+	majorMinorPlaceholder = "@MAJOR_MINOR@"
+)
+
+// replaceVersion func takes s, version, placeholder string input and returns string
+// Occurrences that are part of a longer version are left alone, e.g. 8.6 in
+// 8.6.10, 18.6 or 1.8.6.
+func replaceVersion(s, version, placeholder string) string {
+	if version == "" {
+		return s
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(s, version)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := i + len(version)
+		digit := func(j int) bool { return j >= 0 && j < len(s) && unicode.IsDigit(rune(s[j])) }
+		before := digit(i-1) || i > 0 && s[i-1] == '.' && digit(i-2)
+		after := digit(end) || end < len(s) && s[end] == '.' && digit(end+1)
+		b.WriteString(s[:i])
+		if before || after {
+			b.WriteString(version)
+		} else {
+			b.WriteString(placeholder)
+		}
+		s = s[end:]
+	}
+}
+
+// versionStrings func takes version string input and returns string, string
+// The version and its major.minor, when that is shorter than the version.
+func versionStrings(version string) (string, string) {
+	v := MustParseVersion(version)
+	mm := v.Prefix + v.MajorMinor()
+	if len(v.Parts) < 2 || mm == version || !strings.HasPrefix(version, mm+".") {
+		return version, ""
+	}
+	return version, mm
+}
+
+// templateText func takes s, version string input and returns string
+func templateText(s, version string) string {
+	full, mm := versionStrings(version)
+	s = replaceVersion(s, full, versionPlaceholder)
+	return replaceVersion(s, mm, majorMinorPlaceholder)
+}
+
+// TemplateVersion func takes pkgInfo *PackageInformation input and returns *PackageInformation
+// A copy of the package with its version and major.minor in commands,
+// source archives, the source directory and the patches of fixups replaced
+// by @VERSION@ and @MAJOR_MINOR@.
+func TemplateVersion(pkgInfo *PackageInformation) *PackageInformation {
+	return mapVersionText(pkgInfo, func(s string) string {
+		return templateText(s, pkgInfo.Version)
+	})
+}
+
+// mapVersionText func takes pkgInfo *PackageInformation, f func(string) string input and returns *PackageInformation
+// A copy of the package with f applied to every text holding its version.
+func mapVersionText(pkgInfo *PackageInformation, f func(string) string) *PackageInformation {
+	mapped := *pkgInfo
+	mapped.Commands = make([]Command, len(pkgInfo.Commands))
+	for i, cmd := range pkgInfo.Commands {
+		cmd.Cmd = f(cmd.Cmd)
+		mapped.Commands[i] = cmd
+	}
+	mapped.Sources = make([]Source, len(pkgInfo.Sources))
+	for i, src := range pkgInfo.Sources {
+		src.Archive = f(src.Archive)
+		mapped.Sources[i] = src
+	}
+	mapped.SourceDir = f(pkgInfo.SourceDir)
+	if pkgInfo.Fixups != nil {
+		mapped.Fixups = make([]SourceFixup, len(pkgInfo.Fixups))
+	}
+	for i, fixup := range pkgInfo.Fixups {
+		fixup.Patch, fixup.Source = f(fixup.Patch), f(fixup.Source)
+		fixup.Argv = append([]string(nil), fixup.Argv...)
+		for j, arg := range fixup.Argv {
+			fixup.Argv[j] = f(arg)
+		}
+		mapped.Fixups[i] = fixup
+	}
+	return &mapped
+}
+
+// RenderVersion func takes pkgInfo *PackageInformation, version string input and returns *PackageInformation
+// A copy of a templated package with the placeholders replaced by version
+// and its major.minor.
+func RenderVersion(pkgInfo *PackageInformation, version string) *PackageInformation {
+	full, mm := versionStrings(version)
+	if mm == "" {
+		mm = full
+	}
+	replacer := strings.NewReplacer(versionPlaceholder, full, majorMinorPlaceholder, mm)
+	rendered := mapVersionText(pkgInfo, replacer.Replace)
+	rendered.Version = version
+	rendered.VersionGuessed = false
+	return rendered
+}
+
+// Retarget func takes pkgInfo *PackageInformation, version string input and returns *PackageInformation, error
+// The package's commands and sources are re-rendered for version. Checksums
+// of sources whose archive changed are cleared as they belong to the old
+// release. The source directory and fixup patches follow the version too,
+// other extracted details, such as options, are kept as the book has them.
+func Retarget(pkgInfo *PackageInformation, version string) (*PackageInformation, error) {
+	if _, err := ParseVersion(version); err != nil {
+		return nil, fmt.Errorf("failed to retarget %s : %v", pkgInfo.Name, err)
+	}
+	retargeted := RenderVersion(TemplateVersion(pkgInfo), version)
+	for i, src := range retargeted.Sources {
+		if src.Archive != pkgInfo.Sources[i].Archive {
+			retargeted.Sources[i].MD5Sum = ""
+		}
+	}
+	retargeted.Diagnostics = append(append([]string(nil), pkgInfo.Diagnostics...),
+		fmt.Sprintf("retargeted from %s, source checksums need updating", pkgInfo.Version))
+	return retargeted, nil
+}
+
+// runRetarget func takes args []string input and returns error
+func runRetarget(args []string) error {
+	var (
+		overrides string
+		asjson    bool
+	)
+	flags := flag.NewFlagSet("retarget", flag.ExitOnError)
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&asjson, "json", false, "Output JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s retarget [options] <package> [version]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		return fmt.Errorf("retarget requires a package page or file")
+	}
+	pkgInfo, err := ReadPackageInformation(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 1 {
+		pkgInfo = TemplateVersion(pkgInfo)
+	} else if pkgInfo, err = Retarget(pkgInfo, flags.Arg(1)); err != nil {
+		return err
+	}
+	var content []byte
+	if asjson {
+		content, err = pkgInfo.ToPrettyJSON()
+	} else {
+		content, err = pkgInfo.ToYAML()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", content)
+	return nil
+}
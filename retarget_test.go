@@ -0,0 +1,62 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestTemplateVersion func takes no input and returns t *testing.T
+func TestTemplateVersion(t *testing.T) {
+	pkgInfo := tclPackage(withPatch)
+	pkgInfo.SourceDir = "tcl8.6.9"
+	pkgInfo.Commands = append(pkgInfo.Commands, Command{
+		Cmd:   "install -v -m755 -d /usr/share/doc/tcl-8.6.9 &&\ncp -v -r ../html/* /usr/share/doc/tcl-8.6.9 &&\nln -sv pkgs/tdbc1.1.0 tdbc-18.6 8.6.10",
+		Index: 5,
+	})
+	pkgInfo.Fixups = ExtractFixups(pkgInfo)
+	templated := TemplateVersion(pkgInfo)
+	assert.Equal(t, templated.Sources[0].Archive, "https://downloads.sourceforge.net/tcl/tcl@VERSION@-src.tar.gz")
+	assert.Equal(t, templated.Commands[4].Cmd, "make install &&\nmake install-private-headers &&\nln -v -sf tclsh@MAJOR_MINOR@ /usr/bin/tclsh")
+	assert.Equal(t, templated.Commands[5].Cmd,
+		"install -v -m755 -d /usr/share/doc/tcl-@VERSION@ &&\ncp -v -r ../html/* /usr/share/doc/tcl-@VERSION@ &&\nln -sv pkgs/tdbc1.1.0 tdbc-18.6 8.6.10")
+	assert.Equal(t, pkgInfo.Commands[4].Cmd, "make install &&\nmake install-private-headers &&\nln -v -sf tclsh8.6 /usr/bin/tclsh")
+	assert.Equal(t, templated.SourceDir, "tcl@VERSION@")
+	assert.Equal(t, templated.Fixups[0].Patch, "../tcl-@VERSION@-fix-1.patch")
+	assert.Equal(t, templated.Fixups[0].Source, "http://www.linuxfromscratch.org/patches/blfs/9.0/tcl-@VERSION@-fix-1.patch")
+	assert.DeepEqual(t, templated.Fixups[0].Argv, []string{"patch", "-Np1", "-i", "../tcl-@VERSION@-fix-1.patch"})
+	assert.Equal(t, pkgInfo.Fixups[0].Patch, "../tcl-8.6.9-fix-1.patch")
+
+	rendered := RenderVersion(templated, "8.6.9")
+	assert.DeepEqual(t, rendered.Commands, pkgInfo.Commands)
+	assert.DeepEqual(t, rendered.Sources, pkgInfo.Sources)
+	assert.DeepEqual(t, rendered.Fixups, pkgInfo.Fixups)
+	assert.Equal(t, rendered.SourceDir, pkgInfo.SourceDir)
+}
+
+// TestRetarget func takes no input and returns t *testing.T
+func TestRetarget(t *testing.T) {
+	pkgInfo := tclPackage(withPatch)
+	pkgInfo.SourceDir = "tcl8.6.9"
+	pkgInfo.Fixups = ExtractFixups(pkgInfo)
+	retargeted, err := Retarget(pkgInfo, "8.7.1")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, retargeted.Version, "8.7.1")
+	assert.Equal(t, retargeted.Sources[0].Archive, "https://downloads.sourceforge.net/tcl/tcl8.7.1-src.tar.gz")
+	assert.Equal(t, retargeted.Sources[0].MD5Sum, "")
+	assert.Equal(t, retargeted.SourceDir, "tcl8.7.1")
+	assert.Equal(t, retargeted.Fixups[0].Patch, "../tcl-8.7.1-fix-1.patch")
+	assert.Equal(t, retargeted.Fixups[0].Source, "http://www.linuxfromscratch.org/patches/blfs/9.0/tcl-8.7.1-fix-1.patch")
+	assert.Equal(t, retargeted.Fixups[0].Source, retargeted.Sources[2].Archive)
+	assert.Equal(t, retargeted.Commands[1].Cmd, "tar -xf ../tcl8.7.1-html.tar.gz --strip-components=1")
+	assert.Equal(t, retargeted.Commands[4].Cmd, "make install &&\nmake install-private-headers &&\nln -v -sf tclsh8.7 /usr/bin/tclsh")
+	assert.Equal(t, pkgInfo.Sources[0].MD5Sum, "aa0a121d95a0e7b73a036f26028538d4")
+	assert.Assert(t, is.Contains(retargeted.Diagnostics, "retargeted from 8.6.9, source checksums need updating"))
+
+	_, err = Retarget(pkgInfo, "latest")
+	assert.ErrorContains(t, err, "failed to retarget tcl")
+}
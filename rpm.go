@@ -0,0 +1,209 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// rpmTemplate is the RPM spec file layout
+const rpmTemplate = `# Generated by cmdext from {{ .Name }}-{{ .Version }}
+Name:           {{ .Name }}
+Version:        {{ .RPMVersion }}
+Release:        1%{?dist}
+Summary:        {{ .Summary }}
+License:        Unknown
+URL:            {{ .URL }}
+{{ range .SourceTags }}{{ . }}
+{{ end }}{{ range .BuildRequires }}BuildRequires:  {{ . }}
+{{ end }}{{ range .Requires }}Requires:       {{ . }}
+{{ end }}{{ range .Recommends }}Recommends:     {{ . }}
+{{ end }}{{ range .Suggests }}Suggests:       {{ . }}
+{{ end }}
+%description
+{{ .Description }}
+
+%prep
+{{ .Setup }}
+{{ range .Prep }}
+{{ . }}
+{{ end }}
+%build
+{{ range .Build }}
+{{ . }}
+{{ end }}{{ if .Check }}
+%check
+{{ range .Check }}
+{{ . }}
+{{ end }}{{ end }}
+%install
+{{ range .Install }}
+{{ . }}
+{{ end }}
+%files
+{{ range .Files }}{{ . }}
+{{ else }}# no installed contents are listed for {{ .Name }}
+{{ end }}`
+
+// rpmData struct for rpmdata
+type rpmData struct {
+	*PackageInformation
+	RPMVersion    string
+	Summary       string
+	Description   string
+	URL           string
+	Setup         string
+	SourceTags    []string
+	BuildRequires []string
+	Requires      []string
+	Recommends    []string
+	Suggests      []string
+	Prep          []string
+	Build         []string
+	Check         []string
+	Install       []string
+	Files         []string
+}
+
+// rpmSources func takes pkgInfo *PackageInformation input and returns []string, map[string]string
+// The returned map takes archive file names to their %{SOURCEn} or %{PATCHn} macro.
+func rpmSources(pkgInfo *PackageInformation) ([]string, map[string]string) {
+	tags := make([]string, 0)
+	macros := make(map[string]string)
+	sources, patches := 0, 0
+	for _, src := range emitterSources(pkgInfo) {
+		var tag string
+		if isPatch(src.Archive) {
+			tag = fmt.Sprintf("Patch%d", patches)
+			macros[sourceFile(src.Archive)] = fmt.Sprintf("%%{PATCH%d}", patches)
+			patches++
+		} else {
+			tag = fmt.Sprintf("Source%d", sources)
+			macros[sourceFile(src.Archive)] = fmt.Sprintf("%%{SOURCE%d}", sources)
+			sources++
+		}
+		tags = append(tags, fmt.Sprintf("%-16s%s", tag+":", src.Archive))
+	}
+	return tags, macros
+}
+
+// rpmMacroReplacer func takes macros map[string]string input and returns *strings.Replacer
+// References to ../<archive> are replaced longest name first, so an archive
+// whose name starts with another's is not replaced by the shorter one's macro.
+func rpmMacroReplacer(macros map[string]string) *strings.Replacer {
+	files := make([]string, 0, len(macros))
+	for file := range macros {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if len(files[i]) != len(files[j]) {
+			return len(files[i]) > len(files[j])
+		}
+		return files[i] < files[j]
+	})
+	oldnew := make([]string, 0, 2*len(files))
+	for _, file := range files {
+		oldnew = append(oldnew, "../"+file, macros[file])
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// rpmFiles func takes contents *Contents input and returns []string
+// BLFS installs into /usr, programs to /usr/bin and libraries to /usr/lib.
+func rpmFiles(contents *Contents) []string {
+	files := make([]string, 0)
+	if contents == nil {
+		return files
+	}
+	for _, prog := range contents.Programs {
+		files = append(files, path.Join("/usr/bin", path.Base(prog)))
+	}
+	for _, lib := range contents.Libraries {
+		files = append(files, path.Join("/usr/lib", path.Base(lib))+"*")
+	}
+	for _, dir := range contents.Directories {
+		if strings.HasPrefix(dir, "/") {
+			files = append(files, dir)
+		}
+	}
+	return files
+}
+
+// rpmSetup func takes pkgInfo *PackageInformation, macros map[string]string input and returns string
+// The %setup of the primary source. Without a SourceDir the directory it
+// unpacks to is read from the archive listing when the spec is parsed.
+func rpmSetup(pkgInfo *PackageInformation, macros map[string]string) string {
+	file := unpackedArchive(pkgInfo)
+	switch {
+	case pkgInfo.SourceDir != "":
+		return "%setup -q -n " + pkgInfo.SourceDir
+	case file == "":
+		return "%setup -q -c -T"
+	}
+	return "%setup -q -n %(" + fmt.Sprintf(topDirCommand, macros[file]) + ")"
+}
+
+// EmitRPM func takes pkgInfo *PackageInformation, opts EmitOptions input and returns []byte, error
+// References to ../<archive> in commands use the spec's source macros and
+// install commands are rewritten to install into %{buildroot}. Each section
+// starts in the unpacked source and changes to the directory its first
+// command runs in.
+func EmitRPM(pkgInfo *PackageInformation, opts EmitOptions) ([]byte, error) {
+	primary := primarySource(pkgInfo)
+	tags, macros := rpmSources(pkgInfo)
+	requires := dependencyNames(pkgInfo.Dependencies.Requires)
+	data := rpmData{
+		PackageInformation: pkgInfo,
+		RPMVersion:         strings.ReplaceAll(pkgInfo.Version, "-", "_"),
+		Summary:            summary(pkgInfo.Description),
+		URL:                homepage(primary.Archive),
+		Setup:              rpmSetup(pkgInfo, macros),
+		SourceTags:         tags,
+		BuildRequires:      requires,
+		Requires:           requires,
+		Recommends:         dependencyNames(pkgInfo.Dependencies.Recommended),
+		Suggests:           dependencyNames(pkgInfo.Dependencies.Optional),
+		Files:              rpmFiles(pkgInfo.Contents),
+	}
+	// Description shadows the package's so the collapsed text stays in the spec
+	data.Description = strings.Join(strings.Fields(pkgInfo.Description), " ")
+	// rpmbuild rejects a spec without a Summary or %description
+	if data.Summary == "" {
+		data.Summary = pkgInfo.Name
+	}
+	if data.Description == "" {
+		data.Description = data.Summary
+	}
+	stages := stagedStages(pkgInfo.Commands, opts.StagingDir("%{buildroot}"))
+	dirs := commandDirs(pkgInfo.Commands)
+	replacer := rpmMacroReplacer(macros)
+	render := func(stage string) []string {
+		cmds, _ := stageTexts(stages[stage], dirs, "%{_builddir}/%{buildsubdir}", ".")
+		for i, text := range cmds {
+			cmds[i] = replacer.Replace(text)
+		}
+		return cmds
+	}
+	data.Prep = render(StagePrepare)
+	data.Build = render(StageBuild)
+	data.Check = render(StageCheck)
+	data.Install = render(StageInstall)
+	tmpl, err := ParseTemplate("spec", rpmTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return Render(tmpl, data)
+}
+
+func init() {
+	RegisterEmitter(Emitter{
+		Name: "rpm",
+		Emit: singleFile(func(pkgInfo *PackageInformation) string {
+			return pkgInfo.Name + ".spec"
+		}, EmitRPM),
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestEmitRPM func takes no input and returns t *testing.T
+func TestEmitRPM(t *testing.T) {
+	pkgInfo := tclPackage(withPatch, withContents)
+	content, err := EmitRPM(pkgInfo, EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	spec := string(content)
+	fmt.Printf("%s\n", spec)
+	assert.Assert(t, strings.Contains(spec, "Name:           tcl\nVersion:        8.6.9\n"))
+	assert.Assert(t, strings.Contains(spec, "Source0:        https://downloads.sourceforge.net/tcl/tcl8.6.9-src.tar.gz\n"))
+	assert.Assert(t, strings.Contains(spec, "Source1:        https://downloads.sourceforge.net/tcl/tcl8.6.9-html.tar.gz\n"))
+	assert.Assert(t, strings.Contains(spec, "Patch0:         http://www.linuxfromscratch.org/patches/blfs/9.0/tcl-8.6.9-fix-1.patch\n"))
+	assert.Assert(t, strings.Contains(spec, "BuildRequires:  zlib\nRequires:       zlib\nRecommends:     tk\nSuggests:       doxygen\n"))
+	assert.Assert(t, strings.Contains(spec, "%setup -q -n %(tar -tf %{SOURCE0} | head -n 1 | sed -e 's@^\\./@@' -e 's@/.*@@')\n"))
+	assert.Assert(t, strings.Contains(spec, "patch -Np1 -i %{PATCH0}"))
+	assert.Assert(t, strings.Contains(spec, "tar -xf %{SOURCE1} --strip-components=1"))
+	assert.Assert(t, strings.Contains(spec, "%check\n\ncd \"%{_builddir}/%{buildsubdir}/unix\"\n\nmake test\n"))
+	assert.Assert(t, strings.Contains(spec, "%install\n\ncd \"%{_builddir}/%{buildsubdir}/unix\"\n\nmake DESTDIR=%{buildroot} install &&"))
+	assert.Assert(t, strings.Contains(spec, "%files\n/usr/bin/tclsh\n/usr/bin/tclsh8.6\n/usr/lib/libtcl8.6.so*\n"))
+
+	pkgInfo.Contents = nil
+	content, err = EmitRPM(pkgInfo, EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.Contains(string(content), "%files\n# no installed contents are listed for tcl\n"))
+
+	pkgInfo.SourceDir = "tcl8.6.9"
+	content, err = EmitRPM(pkgInfo, EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.Contains(string(content), "%setup -q -n tcl8.6.9\n"))
+}
+
+// TestRPMMacroReplacer func takes no input and returns t *testing.T
+func TestRPMMacroReplacer(t *testing.T) {
+	macros := map[string]string{
+		"foo-1.0.tar":         "%{SOURCE0}",
+		"foo-1.0.tar.gz":      "%{SOURCE1}",
+		"foo-1.0-fix-1.patch": "%{PATCH0}",
+	}
+	// map order changes between runs, the result must not
+	for i := 0; i < 10; i++ {
+		replaced := rpmMacroReplacer(macros).Replace("tar -xf ../foo-1.0.tar.gz && tar -xf ../foo-1.0.tar")
+		assert.Equal(t, replaced, "tar -xf %{SOURCE1} && tar -xf %{SOURCE0}")
+	}
+}
+
+// TestEmitRPMEmptySummary func takes no input and returns t *testing.T
+func TestEmitRPMEmptySummary(t *testing.T) {
+	content, err := EmitRPM(&PackageInformation{Name: "foo", Version: "1.0"}, EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	spec := string(content)
+	assert.Assert(t, strings.Contains(spec, "Summary:        foo\n"))
+	assert.Assert(t, strings.Contains(spec, "%description\nfoo\n"))
+
+	pkgInfo := &PackageInformation{Name: "foo", Version: "1.0", Description: "The foo\n  package."}
+	content, err = EmitRPM(pkgInfo, EmitOptions{})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.Contains(string(content), "%description\nThe foo package.\n"))
+	assert.Equal(t, pkgInfo.Description, "The foo\n  package.")
+}
@@ -0,0 +1,362 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runStateFile keeps the working directory and exported variables between steps
+const runStateFile = ".cmdext-state"
+
+// runDestdir is the staging directory below the work dir, run commands get it
+// as $DESTDIR
+const runDestdir = "destdir"
+
+// runWrapper runs a step in the state left by the one before and exits with
+// its status. The step runs in a function under set -e, which local - keeps
+// from the rest of the wrapper, so its status is taken from the last command
+// even when that is a failing && list. The state is only saved when the step
+// succeeds, a resumed run retries a failed step from the state before it.
+const runWrapper = `[ -f "$CMDEXT_STATE" ] && . "$CMDEXT_STATE"
+cmdext_step() {
+local -
+set -e
+%s
+}
+cmdext_step
+status=$?
+if [ $status -eq 0 ]; then
+  { export -p; printf 'cd %%q\n' "$PWD"; } > "$CMDEXT_STATE"
+fi
+exit $status
+`
+
+// StepResult struct for stepresult
+type StepResult struct {
+	Index       int     `json:"index" yaml:"index"`
+	Cmd         string  `json:"cmd" yaml:"cmd"`
+	Root        bool    `json:"root,omitempty" yaml:"root,omitempty"`
+	Log         string  `json:"log" yaml:"log"`
+	ExitCode    int     `json:"exit_code" yaml:"exit_code"`
+	Seconds     float64 `json:"seconds" yaml:"seconds"`
+	OutputBytes int64   `json:"output_bytes" yaml:"output_bytes"`
+}
+
+// RunReport struct for runreport
+type RunReport struct {
+	Name    string       `json:"name" yaml:"name"`
+	Version string       `json:"version" yaml:"version"`
+	WorkDir string       `json:"workdir" yaml:"workdir"`
+	SrcDir  string       `json:"srcdir" yaml:"srcdir"`
+	Destdir string       `json:"destdir,omitempty" yaml:"destdir,omitempty"`
+	Started time.Time    `json:"started" yaml:"started"`
+	Failed  bool         `json:"failed" yaml:"failed"`
+	Steps   []StepResult `json:"steps" yaml:"steps"`
+}
+
+// RunOptions struct for runoptions
+// Steps with an index below From are skipped, resuming an earlier run.
+// AllowRoot runs install and root steps on the host as the book has them
+// instead of staging them below the work dir.
+type RunOptions struct {
+	WorkDir   string
+	Sources   string
+	From      int
+	DryRun    bool
+	AllowRoot bool
+	Output    io.Writer
+}
+
+// countWriter struct for countwriter
+type countWriter struct {
+	n int64
+}
+
+// Write func takes p []byte input and returns int, error
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// sortedCommands func takes cmds []Command input and returns []Command
+func sortedCommands(cmds []Command) []Command {
+	sorted := append([]Command(nil), cmds...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Index < sorted[j].Index
+	})
+	return sorted
+}
+
+// archiveTopDir func takes dir, file string input and returns string, error
+// The top directory in the listing of the archive file in dir, "." when the
+// archive has none.
+func archiveTopDir(dir, file string) (string, error) {
+	list := exec.Command("tar", "-tf", file)
+	list.Dir = dir
+	out, err := list.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s : %v", file, err)
+	}
+	top := strings.TrimPrefix(strings.SplitN(string(out), "\n", 2)[0], "./")
+	if i := strings.Index(top, "/"); i >= 0 {
+		top = top[:i]
+	}
+	if top == "" {
+		top = "."
+	}
+	return top, nil
+}
+
+// unpackSource func takes pkgInfo *PackageInformation, opts RunOptions input and returns string, error
+// Sources are linked into the work dir and the primary source is unpacked
+// there. The returned directory is where the first command runs.
+func unpackSource(pkgInfo *PackageInformation, opts RunOptions) (string, error) {
+	primary := primarySource(pkgInfo)
+	if primary.Archive == "" || isPatch(primary.Archive) {
+		return opts.WorkDir, nil
+	}
+	if opts.Sources == "" {
+		return "", fmt.Errorf("%s needs a sources directory holding %s", pkgInfo.Name, sourceFile(primary.Archive))
+	}
+	for _, src := range emitterSources(pkgInfo) {
+		file, err := filepath.Abs(filepath.Join(opts.Sources, sourceFile(src.Archive)))
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(file); err != nil {
+			return "", fmt.Errorf("failed to find source %s : %v", sourceFile(src.Archive), err)
+		}
+		link := filepath.Join(opts.WorkDir, sourceFile(src.Archive))
+		_ = os.Remove(link)
+		if err := os.Symlink(file, link); err != nil {
+			return "", err
+		}
+	}
+	top := pkgInfo.SourceDir
+	if top == "" {
+		var err error
+		if top, err = archiveTopDir(opts.WorkDir, sourceFile(primary.Archive)); err != nil {
+			return "", err
+		}
+	}
+	srcdir := filepath.Join(opts.WorkDir, top)
+	if rel, err := filepath.Rel(opts.WorkDir, srcdir); err != nil || filepath.IsAbs(top) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("source dir %q of %s is not below the work dir", top, pkgInfo.Name)
+	}
+	if srcdir != opts.WorkDir {
+		if err := os.RemoveAll(srcdir); err != nil {
+			return "", err
+		}
+	}
+	tar := exec.Command("tar", "-xf", sourceFile(primary.Archive))
+	tar.Dir = opts.WorkDir
+	if out, err := tar.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to unpack %s : %v\n%s", sourceFile(primary.Archive), err, out)
+	}
+	return srcdir, nil
+}
+
+// runCommands func takes pkgInfo *PackageInformation, opts RunOptions input and returns []Command, error
+// The commands in Index order. Install and root steps are staged into
+// $DESTDIR unless opts.AllowRoot is set, and a staged step that still writes
+// outside of it is refused.
+func runCommands(pkgInfo *PackageInformation, opts RunOptions) ([]Command, error) {
+	cmds := sortedCommands(pkgInfo.Commands)
+	if opts.AllowRoot {
+		return cmds, nil
+	}
+	cmds = StageCommands(cmds, `"$DESTDIR"`)
+	for _, cmd := range cmds {
+		if cmd.Index >= opts.From && len(cmd.StagingIssues) > 0 {
+			return cmds, fmt.Errorf("step %d of %s is not staged, %s : rerun with -allow-root to run it on the host", cmd.Index, pkgInfo.Name, strings.Join(cmd.StagingIssues, ", "))
+		}
+	}
+	return cmds, nil
+}
+
+// runStep func takes cmd Command, dir, state, log string input and returns StepResult
+// The command runs through bash under set -e with its output streamed to the
+// log file.
+func runStep(cmd Command, dir, state, log string, env ...string) (result StepResult) {
+	result = StepResult{Index: cmd.Index, Cmd: cmd.Cmd, Root: cmd.Root, Log: log}
+	start := time.Now()
+	defer func() {
+		result.Seconds = time.Since(start).Seconds()
+	}()
+	f, err := os.Create(log)
+	if err != nil {
+		result.ExitCode = -1
+		return result
+	}
+	defer f.Close()
+	counter := &countWriter{}
+	out := io.MultiWriter(f, counter)
+	bash := exec.Command("bash", "-c", fmt.Sprintf(runWrapper, cmd.Cmd))
+	bash.Dir = dir
+	bash.Env = append(append(os.Environ(), env...), "CMDEXT_STATE="+state)
+	bash.Stdout = out
+	bash.Stderr = out
+	err = bash.Run()
+	result.OutputBytes = counter.n
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		result.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		fmt.Fprintf(f, "%v\n", err)
+		result.ExitCode = -1
+	}
+	return result
+}
+
+// readRunReport func takes file string input and returns *RunReport, error
+func readRunReport(file string) (*RunReport, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	report := &RunReport{}
+	if err := json.Unmarshal(b, report); err != nil {
+		return nil, fmt.Errorf("failed to read report %s : %v", file, err)
+	}
+	return report, nil
+}
+
+// RunCommands func takes pkgInfo *PackageInformation, opts RunOptions input and returns *RunReport, error
+// Commands run in Index order and the run stops at the first failing step.
+// Logs and the report are written to the logs directory of the work dir.
+// Resumed runs keep the earlier steps of the previous report and the working
+// directory and environment the last successful step left. Install and root
+// steps install into the destdir of the work dir, or on the host with
+// opts.AllowRoot.
+func RunCommands(pkgInfo *PackageInformation, opts RunOptions) (*RunReport, error) {
+	cmds, err := runCommands(pkgInfo, opts)
+	if opts.DryRun {
+		for _, cmd := range cmds {
+			if cmd.Index < opts.From {
+				continue
+			}
+			user := "user"
+			if cmd.Root {
+				user = "root"
+			}
+			where := "staged"
+			if opts.AllowRoot {
+				where = "on the host"
+			}
+			fmt.Fprintf(opts.Output, "# step %d (%s, %s)\n%s\n\n", cmd.Index, user, where, stagedText(cmd))
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	workdir, err := filepath.Abs(opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	opts.WorkDir = workdir
+	logs := filepath.Join(workdir, "logs")
+	if err := os.MkdirAll(logs, 0755); err != nil {
+		return nil, err
+	}
+	reportFile := filepath.Join(logs, "report.json")
+	state := filepath.Join(logs, runStateFile)
+	report := &RunReport{Name: pkgInfo.Name, Version: pkgInfo.Version, WorkDir: workdir, Started: time.Now()}
+	var env []string
+	if !opts.AllowRoot {
+		report.Destdir = filepath.Join(workdir, runDestdir)
+		if err := os.MkdirAll(report.Destdir, 0755); err != nil {
+			return nil, err
+		}
+		env = append(env, "DESTDIR="+report.Destdir)
+	}
+	if opts.From > 0 {
+		previous, err := readRunReport(reportFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resume %s : %v", pkgInfo.Name, err)
+		}
+		report.SrcDir = previous.SrcDir
+		for _, step := range previous.Steps {
+			if step.Index < opts.From {
+				report.Steps = append(report.Steps, step)
+			}
+		}
+	} else {
+		_ = os.Remove(state)
+		if report.SrcDir, err = unpackSource(pkgInfo, opts); err != nil {
+			return nil, err
+		}
+	}
+	for _, cmd := range cmds {
+		if cmd.Index < opts.From {
+			continue
+		}
+		log := filepath.Join(logs, fmt.Sprintf("step-%03d.log", cmd.Index))
+		fmt.Fprintf(opts.Output, "==> step %d\n", cmd.Index)
+		result := runStep(cmd, report.SrcDir, state, log, env...)
+		report.Steps = append(report.Steps, result)
+		if result.ExitCode != 0 {
+			report.Failed = true
+			fmt.Fprintf(opts.Output, "step %d failed with exit code %d, see %s\n", cmd.Index, result.ExitCode, log)
+			break
+		}
+	}
+	jsn, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to json : %v", err)
+	}
+	if err := ioutil.WriteFile(reportFile, jsn, 0644); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// runRun func takes args []string input and returns error
+func runRun(args []string) error {
+	var overrides string
+	opts := RunOptions{Output: os.Stdout}
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.StringVar(&opts.WorkDir, "workdir", "", "Directory to unpack and build in (default /tmp/cmdext/<name>)")
+	flags.StringVar(&opts.Sources, "sources", "", "Path to a directory of downloaded source archives")
+	flags.IntVar(&opts.From, "from", 0, "Resume a previous run from this command index")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "Print the commands instead of running them")
+	flags.BoolVar(&opts.AllowRoot, "allow-root", false, "Run install and root commands on the host instead of staging them into <workdir>/destdir")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s run [options] <package>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("run requires a package page or file")
+	}
+	pkgInfo, err := ReadPackageInformation(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	if opts.WorkDir == "" {
+		opts.WorkDir = filepath.Join(os.TempDir(), "cmdext", pkgInfo.Name)
+	}
+	report, err := RunCommands(pkgInfo, opts)
+	if err != nil {
+		return err
+	}
+	if report != nil && report.Failed {
+		return fmt.Errorf("%s failed, resume with -from %d", pkgInfo.Name, report.Steps[len(report.Steps)-1].Index)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// testRunPage is a package page whose commands are harmless shell
+const testRunPage = `<html>
+  <head><title>hello-1.0</title></head>
+  <body>
+    <div class="sect1">
+      <h1 class="sect1"><a id="hello" name="hello"></a>hello-1.0</h1>
+      <div class="package">
+        <p>The <span class="application">hello</span> package.</p>
+      </div>
+      <div class="installation">
+        <pre class="userinput"><kbd class="command">mkdir -p build &amp;&amp;
+cd build</kbd></pre>
+        <pre class="userinput"><kbd class="command">export GREETING=hello</kbd></pre>
+        <pre class="userinput"><kbd class="command">echo "$GREETING" &gt; greeting.txt &amp;&amp;
+test -f "$MARKER"</kbd></pre>
+        <pre class="userinput"><kbd class="command">pwd &gt; done.txt</kbd></pre>
+      </div>
+    </div>
+  </body>
+</html>
+`
+
+// TestRunCommands func takes no input and returns t *testing.T
+func TestRunCommands(t *testing.T) {
+	dir := writeBook(t, map[string]string{"hello.html": testRunPage})
+	defer os.RemoveAll(dir)
+	pkgInfo, err := ReadPackageInformation(filepath.Join(dir, "hello.html"), "")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(pkgInfo.Commands), 4)
+
+	var out bytes.Buffer
+	workdir := filepath.Join(dir, "work")
+	_, err = RunCommands(pkgInfo, RunOptions{WorkDir: workdir, DryRun: true, From: 1, Output: &out})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.HasPrefix(out.String(), "# step 1 (user, staged)\nexport GREETING=hello\n"))
+	_, err = os.Stat(workdir)
+	assert.Assert(t, os.IsNotExist(err))
+
+	marker := filepath.Join(dir, "marker")
+	os.Setenv("MARKER", marker)
+	defer os.Unsetenv("MARKER")
+	report, err := RunCommands(pkgInfo, RunOptions{WorkDir: workdir, Output: &out})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, report.Failed)
+	assert.Equal(t, len(report.Steps), 3)
+	assert.Equal(t, report.Steps[2].ExitCode, 1)
+	greeting, err := ioutil.ReadFile(filepath.Join(workdir, "build", "greeting.txt"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, string(greeting), "hello\n")
+
+	err = ioutil.WriteFile(marker, nil, 0644)
+	assert.Assert(t, is.Nil(err))
+	report, err = RunCommands(pkgInfo, RunOptions{WorkDir: workdir, From: 2, Output: &out})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, !report.Failed)
+	assert.Equal(t, len(report.Steps), 4)
+	assert.Equal(t, report.Steps[0].OutputBytes, int64(0))
+	assert.Assert(t, report.Steps[3].Seconds > 0)
+	done, err := ioutil.ReadFile(filepath.Join(workdir, "build", "done.txt"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, strings.TrimSpace(string(done)), filepath.Join(workdir, "build"))
+
+	saved, err := readRunReport(filepath.Join(workdir, "logs", "report.json"))
+	assert.Assert(t, is.Nil(err))
+	assert.DeepEqual(t, saved.Steps[3].Log, filepath.Join(workdir, "logs", "step-003.log"))
+}
+
+// TestRunCommandsStaged func takes no input and returns t *testing.T
+func TestRunCommandsStaged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runstaged")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(dir)
+	workdir := filepath.Join(dir, "work")
+	pkgInfo := &PackageInformation{Name: "hello", Version: "1.0", Commands: []Command{
+		{Cmd: "echo hello > hello.txt", Index: 0},
+		{Cmd: "mkdir -p /usr/share/hello &&\ncp hello.txt /usr/share/hello", Index: 1, Root: true},
+	}}
+	var out bytes.Buffer
+	report, err := RunCommands(pkgInfo, RunOptions{WorkDir: workdir, Output: &out})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, !report.Failed)
+	assert.Equal(t, report.Destdir, filepath.Join(workdir, "destdir"))
+	hello, err := ioutil.ReadFile(filepath.Join(workdir, "destdir", "usr", "share", "hello", "hello.txt"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, string(hello), "hello\n")
+
+	pkgInfo.Commands = append(pkgInfo.Commands, Command{Cmd: "useradd -c hello hello", Index: 2, Root: true})
+	out.Reset()
+	_, err = RunCommands(pkgInfo, RunOptions{WorkDir: workdir, DryRun: true, From: 2, Output: &out})
+	assert.ErrorContains(t, err, "step 2 of hello is not staged, useradd changes the running system")
+	assert.Equal(t, out.String(), "# step 2 (root, staged)\n# cmdext: not staged, useradd changes the running system\nuseradd -c hello hello\n\n")
+	_, err = RunCommands(pkgInfo, RunOptions{WorkDir: workdir, Output: &out})
+	assert.ErrorContains(t, err, "rerun with -allow-root")
+
+	out.Reset()
+	_, err = RunCommands(pkgInfo, RunOptions{WorkDir: workdir, DryRun: true, From: 1, AllowRoot: true, Output: &out})
+	assert.Assert(t, is.Nil(err))
+	assert.Assert(t, strings.HasPrefix(out.String(), "# step 1 (root, on the host)\nmkdir -p /usr/share/hello &&\n"))
+}
+
+// TestUnpackSourceOutside func takes no input and returns t *testing.T
+func TestUnpackSourceOutside(t *testing.T) {
+	dir, err := ioutil.TempDir("", "unpack")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(dir)
+	workdir := filepath.Join(dir, "work")
+	keep := filepath.Join(dir, "keep")
+	for _, d := range []string{workdir, keep} {
+		err = os.Mkdir(d, 0755)
+		assert.Assert(t, is.Nil(err))
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "hello-1.0.tar"), nil, 0644)
+	assert.Assert(t, is.Nil(err))
+	for _, top := range []string{"../keep", "..", "/etc"} {
+		pkgInfo := &PackageInformation{Name: "hello", SourceDir: top, Sources: []Source{{Archive: "http://example.com/hello-1.0.tar"}}}
+		_, err = unpackSource(pkgInfo, RunOptions{WorkDir: workdir, Sources: dir})
+		assert.ErrorContains(t, err, "is not below the work dir", top)
+	}
+	_, err = os.Stat(keep)
+	assert.Assert(t, is.Nil(err))
+}
+
+// TestRunStep func takes no input and returns t *testing.T
+func TestRunStep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runstep")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(dir)
+	err = os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	assert.Assert(t, is.Nil(err))
+	state := filepath.Join(dir, runStateFile)
+	log := filepath.Join(dir, "step.log")
+
+	result := runStep(Command{Cmd: "cd sub &&\nexport STEP=one &&\nsleep 0.1", Index: 0}, dir, state, log)
+	assert.Equal(t, result.ExitCode, 0)
+	assert.Assert(t, result.Seconds >= 0.1, "seconds %f", result.Seconds)
+
+	result = runStep(Command{Cmd: "test \"$STEP\" = one &&\ntest \"$(basename \"$PWD\")\" = sub &&\nexport STEP=two &&\nfalse", Index: 1}, dir, state, log)
+	assert.Equal(t, result.ExitCode, 1)
+	result = runStep(Command{Cmd: "test \"$STEP\" = one", Index: 1}, dir, state, log)
+	assert.Equal(t, result.ExitCode, 0)
+
+	result = runStep(Command{Cmd: "false\necho not reached > reached.txt", Index: 2}, dir, state, log)
+	assert.Equal(t, result.ExitCode, 1)
+	_, err = os.Stat(filepath.Join(dir, "sub", "reached.txt"))
+	assert.Assert(t, os.IsNotExist(err))
+	result = runStep(Command{Cmd: "exit 3", Index: 3}, dir, state, log)
+	assert.Equal(t, result.ExitCode, 3)
+}
+
+// TestArchiveTopDir func takes no input and returns t *testing.T
+func TestArchiveTopDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "topdir")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(dir)
+	err = os.MkdirAll(filepath.Join(dir, "tcl8.6.9", "unix"), 0755)
+	assert.Assert(t, is.Nil(err))
+	err = ioutil.WriteFile(filepath.Join(dir, "tcl8.6.9", "unix", "configure"), []byte("#!/bin/sh\n"), 0755)
+	assert.Assert(t, is.Nil(err))
+	tar := exec.Command("tar", "-cf", "tcl8.6.9-src.tar", "./tcl8.6.9")
+	tar.Dir = dir
+	out, err := tar.CombinedOutput()
+	assert.Assert(t, is.Nil(err), string(out))
+
+	top, err := archiveTopDir(dir, "tcl8.6.9-src.tar")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, top, "tcl8.6.9")
+	_, err = archiveTopDir(dir, "missing.tar")
+	assert.ErrorContains(t, err, "failed to list missing.tar")
+}
@@ -0,0 +1,324 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Assignment struct for assignment
+type Assignment struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// Redirect struct for redirect
+// Heredoc holds the body of a here-document.
+type Redirect struct {
+	Op      string `json:"op" yaml:"op"`
+	Fd      string `json:"fd,omitempty" yaml:"fd,omitempty"`
+	Target  string `json:"target" yaml:"target"`
+	Heredoc string `json:"heredoc,omitempty" yaml:"heredoc,omitempty"`
+}
+
+// Step struct for step
+// A simple command of a Command. Command is the Command's index, Line the
+// line of the step within it and Dir the directory it runs in, relative to
+// the unpacked source.
+type Step struct {
+	Command   int          `json:"command" yaml:"command"`
+	Line      int          `json:"line" yaml:"line"`
+	Dir       string       `json:"dir" yaml:"dir"`
+	Env       []Assignment `json:"env,omitempty" yaml:"env,omitempty"`
+	Argv      []string     `json:"argv" yaml:"argv"`
+	Redirects []Redirect   `json:"redirects,omitempty" yaml:"redirects,omitempty"`
+}
+
+// Name func takes no input and returns string
+// The name of the program the step runs, without its directory.
+func (s Step) Name() string {
+	if len(s.Argv) == 0 {
+		return ""
+	}
+	return path.Base(s.Argv[0])
+}
+
+// parseShell func takes cmd string input and returns *syntax.File, error
+func parseShell(cmd string) (*syntax.File, error) {
+	file, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(cmd), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse command : %v", err)
+	}
+	return file, nil
+}
+
+// nodeText func takes cmd string, node syntax.Node input and returns string
+// The source text of node.
+func nodeText(cmd string, node syntax.Node) string {
+	start, end := int(node.Pos().Offset()), int(node.End().Offset())
+	if start > end || end > len(cmd) {
+		return ""
+	}
+	return cmd[start:end]
+}
+
+// wordValue func takes cmd string, word *syntax.Word input and returns string
+// Words made of literals and quoted literals are unquoted, any other word is
+// returned as written, e.g. "$SRCDIR/unix" or $(pwd).
+func wordValue(cmd string, word *syntax.Word) string {
+	if word == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, part := range word.Parts {
+		switch p := part.(type) {
+		case *syntax.Lit:
+			b.WriteString(unescape(p.Value))
+		case *syntax.SglQuoted:
+			b.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			for _, inner := range p.Parts {
+				lit, ok := inner.(*syntax.Lit)
+				if !ok {
+					return nodeText(cmd, word)
+				}
+				b.WriteString(lit.Value)
+			}
+		default:
+			return nodeText(cmd, word)
+		}
+	}
+	return b.String()
+}
+
+// unescape func takes s string input and returns string
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// changeDir func takes dir, to string input and returns string
+func changeDir(dir, to string) string {
+	switch {
+	case to == "" || to == "~":
+		return "~"
+	case path.IsAbs(to) || strings.HasPrefix(to, "$") || strings.HasPrefix(to, "~"):
+		return path.Clean(to)
+	}
+	return path.Join(dir, to)
+}
+
+// walkShell func takes cmd string, stmts []*syntax.Stmt, dir string, fn func(*syntax.Stmt, string) input and returns string
+// fn is called for every simple command and declaration with the directory
+// it runs in, following cd. Directory changes in subshells and pipelines do
+// not outlast them. The directory after the statements is returned.
+func walkShell(cmd string, stmts []*syntax.Stmt, dir string, fn func(s *syntax.Stmt, dir string)) string {
+	for _, s := range stmts {
+		dir = walkStmt(cmd, s, dir, fn)
+	}
+	return dir
+}
+
+// walkStmt func takes cmd string, s *syntax.Stmt, dir string, fn func(*syntax.Stmt, string) input and returns string
+func walkStmt(cmd string, s *syntax.Stmt, dir string, fn func(s *syntax.Stmt, dir string)) string {
+	switch c := s.Cmd.(type) {
+	case *syntax.CallExpr:
+		fn(s, dir)
+		if len(c.Args) > 0 && c.Args[0].Lit() == "cd" {
+			to := ""
+			if len(c.Args) > 1 {
+				to = wordValue(cmd, c.Args[len(c.Args)-1])
+			}
+			if to != "-" {
+				dir = changeDir(dir, to)
+			}
+		}
+	case *syntax.DeclClause:
+		fn(s, dir)
+	case *syntax.BinaryCmd:
+		if c.Op == syntax.Pipe || c.Op == syntax.PipeAll {
+			walkStmt(cmd, c.X, dir, fn)
+			walkStmt(cmd, c.Y, dir, fn)
+			return dir
+		}
+		dir = walkStmt(cmd, c.X, dir, fn)
+		dir = walkStmt(cmd, c.Y, dir, fn)
+	case *syntax.Subshell:
+		walkShell(cmd, c.Stmts, dir, fn)
+	case *syntax.Block:
+		dir = walkShell(cmd, c.Stmts, dir, fn)
+	case *syntax.IfClause:
+		for clause := c; clause != nil; clause = clause.Else {
+			walkShell(cmd, clause.Cond, dir, fn)
+			walkShell(cmd, clause.Then, dir, fn)
+		}
+	case *syntax.WhileClause:
+		walkShell(cmd, c.Cond, dir, fn)
+		walkShell(cmd, c.Do, dir, fn)
+	case *syntax.ForClause:
+		walkShell(cmd, c.Do, dir, fn)
+	case *syntax.CaseClause:
+		for _, item := range c.Items {
+			walkShell(cmd, item.Stmts, dir, fn)
+		}
+	}
+	return dir
+}
+
+// heredocBody func takes cmd string, r *syntax.Redirect input and returns string
+// Leading tabs are removed from the lines of a <<- here-document.
+func heredocBody(cmd string, r *syntax.Redirect) string {
+	body := r.Hdoc.Lit()
+	if body == "" {
+		body = nodeText(cmd, r.Hdoc)
+		if i := strings.LastIndex(body, "\n"); i >= 0 {
+			body = body[:i+1]
+		}
+	}
+	if r.Op == syntax.DashHdoc {
+		lines := strings.SplitAfter(body, "\n")
+		for i := range lines {
+			lines[i] = strings.TrimLeft(lines[i], "\t")
+		}
+		body = strings.Join(lines, "")
+	}
+	return body
+}
+
+// stmtStep func takes cmd string, s *syntax.Stmt, dir string input and returns Step
+func stmtStep(cmd string, s *syntax.Stmt, dir string) Step {
+	step := Step{Line: int(s.Pos().Line()), Dir: dir, Argv: []string{}}
+	switch c := s.Cmd.(type) {
+	case *syntax.CallExpr:
+		for _, a := range c.Assigns {
+			step.Env = append(step.Env, Assignment{Name: a.Name.Value, Value: wordValue(cmd, a.Value)})
+		}
+		for _, w := range c.Args {
+			step.Argv = append(step.Argv, wordValue(cmd, w))
+		}
+	case *syntax.DeclClause:
+		step.Argv = append(step.Argv, c.Variant.Value)
+		for _, a := range c.Args {
+			switch {
+			case a.Naked && a.Name != nil:
+				step.Argv = append(step.Argv, a.Name.Value)
+			case a.Naked:
+				step.Argv = append(step.Argv, wordValue(cmd, a.Value))
+			default:
+				step.Argv = append(step.Argv, a.Name.Value+"="+wordValue(cmd, a.Value))
+			}
+		}
+	}
+	for _, r := range s.Redirs {
+		redirect := Redirect{Op: r.Op.String(), Target: wordValue(cmd, r.Word)}
+		if r.N != nil {
+			redirect.Fd = r.N.Value
+		}
+		if r.Hdoc != nil {
+			redirect.Heredoc = heredocBody(cmd, r)
+		}
+		step.Redirects = append(step.Redirects, redirect)
+	}
+	return step
+}
+
+// ParseSteps func takes cmd Command, dir string input and returns []Step, string, error
+// The command is parsed with a POSIX shell parser, starting in dir. The
+// directory the command leaves the shell in is returned with its steps.
+func ParseSteps(cmd Command, dir string) ([]Step, string, error) {
+	file, err := parseShell(cmd.Cmd)
+	if err != nil {
+		return nil, dir, err
+	}
+	steps := make([]Step, 0)
+	dir = walkShell(cmd.Cmd, file.Stmts, dir, func(s *syntax.Stmt, dir string) {
+		step := stmtStep(cmd.Cmd, s, dir)
+		step.Command = cmd.Index
+		steps = append(steps, step)
+	})
+	return steps, dir, nil
+}
+
+// PackageSteps func takes pkgInfo *PackageInformation input and returns []Step, []error
+// Commands are parsed in Index order as if run in one shell from the unpacked
+// source. Commands that fail to parse are reported and skipped.
+func PackageSteps(pkgInfo *PackageInformation) ([]Step, []error) {
+	var (
+		steps []Step
+		errs  []error
+		dir   = "."
+	)
+	for _, cmd := range sortedCommands(pkgInfo.Commands) {
+		s, next, err := ParseSteps(cmd, dir)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s command %d : %v", pkgInfo.Name, cmd.Index, err))
+			continue
+		}
+		steps = append(steps, s...)
+		dir = next
+	}
+	return steps, errs
+}
+
+// runSteps func takes args []string input and returns error
+func runSteps(args []string) error {
+	var (
+		overrides string
+		asjson    bool
+	)
+	flags := flag.NewFlagSet("steps", flag.ExitOnError)
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&asjson, "json", false, "Output JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s steps [options] <package>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("steps requires a package page or file")
+	}
+	pkgInfo, err := ReadPackageInformation(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	steps, errs := PackageSteps(pkgInfo)
+	for _, err := range errs {
+		warn(err)
+	}
+	var content []byte
+	if asjson {
+		content, err = json.MarshalIndent(steps, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to convert to json : %v", err)
+		}
+		content = append(content, '\n')
+	} else {
+		content, err = yaml.Marshal(steps)
+		if err != nil {
+			return fmt.Errorf("failed to convert to yaml : %v", err)
+		}
+	}
+	fmt.Printf("%s", content)
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestParseSteps func takes no input and returns t *testing.T
+func TestParseSteps(t *testing.T) {
+	tcl := Command{Cmd: "export SRCDIR=`pwd` &&\n\ncd unix &&\n\n./configure --prefix=/usr \\\n            --mandir=/usr/share/man &&\nmake", Index: 1}
+	steps, dir, err := ParseSteps(tcl, ".")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, dir, "unix")
+	assert.Equal(t, len(steps), 4)
+	assert.DeepEqual(t, steps[0].Argv, []string{"export", "SRCDIR=`pwd`"})
+	assert.DeepEqual(t, steps[1].Argv, []string{"cd", "unix"})
+	assert.DeepEqual(t, steps[2].Argv, []string{"./configure", "--prefix=/usr", "--mandir=/usr/share/man"})
+	assert.Equal(t, steps[2].Dir, "unix")
+	assert.Equal(t, steps[2].Line, 5)
+	assert.Equal(t, steps[2].Name(), "configure")
+	assert.Equal(t, steps[3].Command, 1)
+
+	cmd := Command{Cmd: `CC="gcc -O2" ./configure --with-x=no 2>&1 | tee log &&
+( cd doc && make html ) &&
+cat > /etc/foo.conf << "EOF"
+# foo
+EOF
+sed -i 's/a b/c/' Makefile`, Index: 4}
+	steps, dir, err = ParseSteps(cmd, "build")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, dir, "build")
+	assert.Equal(t, len(steps), 6)
+	assert.DeepEqual(t, steps[0].Env, []Assignment{{Name: "CC", Value: "gcc -O2"}})
+	assert.DeepEqual(t, steps[0].Redirects, []Redirect{{Op: ">&", Fd: "2", Target: "1"}})
+	assert.DeepEqual(t, steps[1].Argv, []string{"tee", "log"})
+	assert.Equal(t, steps[3].Dir, "build/doc")
+	assert.DeepEqual(t, steps[4].Redirects, []Redirect{
+		{Op: ">", Target: "/etc/foo.conf"},
+		{Op: "<<", Target: "EOF", Heredoc: "# foo\n"},
+	})
+	assert.DeepEqual(t, steps[5].Argv, []string{"sed", "-i", "s/a b/c/", "Makefile"})
+	assert.Equal(t, steps[5].Dir, "build")
+
+	_, _, err = ParseSteps(Command{Cmd: "make ("}, ".")
+	assert.ErrorContains(t, err, "failed to parse command")
+}
+
+// TestPackageSteps func takes no input and returns t *testing.T
+func TestPackageSteps(t *testing.T) {
+	pkgInfo := &PackageInformation{
+		Name: "tcl",
+		Commands: []Command{
+			{Cmd: "cd unix &&\n./configure --prefix=/usr &&\nmake", Index: 0},
+			{Cmd: "make install &&\nln -v -sf tclsh8.6 /usr/bin/tclsh", Index: 1, Root: true},
+			{Cmd: "if then fi", Index: 4},
+		},
+	}
+	steps, errs := PackageSteps(pkgInfo)
+	assert.Equal(t, len(errs), 1)
+	assert.ErrorContains(t, errs[0], "tcl command 4")
+	assert.Equal(t, steps[len(steps)-1].Dir, "unix")
+	assert.DeepEqual(t, steps[len(steps)-1].Argv, []string{"ln", "-v", "-sf", "tclsh8.6", "/usr/bin/tclsh"})
+}
@@ -0,0 +1,183 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	// register the pure Go sqlite driver
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema is the schema documented in docs/sqlite.md
+const sqliteSchema = `
+CREATE TABLE packages (
+	id              INTEGER PRIMARY KEY,
+	name            TEXT NOT NULL,
+	version         TEXT NOT NULL,
+	version_guessed INTEGER NOT NULL DEFAULT 0,
+	description     TEXT NOT NULL
+);
+CREATE INDEX packages_name ON packages (name);
+
+CREATE TABLE sources (
+	package_id INTEGER NOT NULL REFERENCES packages (id),
+	position   INTEGER NOT NULL,
+	archive    TEXT NOT NULL,
+	md5sum     TEXT NOT NULL,
+	size       TEXT NOT NULL,
+	ondisk     TEXT NOT NULL,
+	build_time TEXT NOT NULL,
+	sbu        REAL
+);
+
+CREATE TABLE commands (
+	package_id INTEGER NOT NULL REFERENCES packages (id),
+	position   INTEGER NOT NULL,
+	cmd        TEXT NOT NULL
+);
+
+CREATE TABLE dependencies (
+	package_id INTEGER NOT NULL REFERENCES packages (id),
+	kind       TEXT NOT NULL CHECK (kind IN ('required', 'recommended', 'optional')),
+	name       TEXT NOT NULL,
+	reference  TEXT NOT NULL
+);
+CREATE INDEX dependencies_name ON dependencies (name);
+
+CREATE TABLE contents (
+	package_id INTEGER NOT NULL REFERENCES packages (id),
+	kind       TEXT NOT NULL CHECK (kind IN ('program', 'library', 'directory')),
+	name       TEXT NOT NULL
+);
+`
+
+// sbuPattern matches the SBU figure of a build time such as "0.2 SBU (Using parallelism=4)"
+var sbuPattern = regexp.MustCompile(`([0-9]*\.?[0-9]+)\s*SBU`)
+
+// parseSBU func takes buildTime string input and returns sql.NullFloat64
+func parseSBU(buildTime string) sql.NullFloat64 {
+	m := sbuPattern.FindStringSubmatch(buildTime)
+	if m == nil {
+		return sql.NullFloat64{}
+	}
+	sbu, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: sbu, Valid: true}
+}
+
+// exportPackage func takes tx *sql.Tx, pkgInfo *PackageInformation input and returns error
+func exportPackage(tx *sql.Tx, pkgInfo *PackageInformation) error {
+	res, err := tx.Exec(`INSERT INTO packages (name, version, version_guessed, description) VALUES (?, ?, ?, ?)`,
+		pkgInfo.Name, pkgInfo.Version, pkgInfo.VersionGuessed, pkgInfo.Description)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for i, src := range pkgInfo.Sources {
+		_, err = tx.Exec(`INSERT INTO sources (package_id, position, archive, md5sum, size, ondisk, build_time, sbu) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, i, src.Archive, src.MD5Sum, src.Size, src.OnDisk, src.BuildTime, parseSBU(src.BuildTime))
+		if err != nil {
+			return err
+		}
+	}
+	for _, cmd := range pkgInfo.Commands {
+		_, err = tx.Exec(`INSERT INTO commands (package_id, position, cmd) VALUES (?, ?, ?)`, id, cmd.Index, cmd.Cmd)
+		if err != nil {
+			return err
+		}
+	}
+	deps := map[string][]string{
+		"required":    pkgInfo.Dependencies.Requires,
+		"recommended": pkgInfo.Dependencies.Recommended,
+		"optional":    pkgInfo.Dependencies.Optional,
+	}
+	for _, kind := range []string{"required", "recommended", "optional"} {
+		for _, dep := range deps[kind] {
+			_, err = tx.Exec(`INSERT INTO dependencies (package_id, kind, name, reference) VALUES (?, ?, ?, ?)`,
+				id, kind, DependencyName(dep), dep)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if pkgInfo.Contents == nil {
+		return nil
+	}
+	contents := map[string][]string{
+		"program":   pkgInfo.Contents.Programs,
+		"library":   pkgInfo.Contents.Libraries,
+		"directory": pkgInfo.Contents.Directories,
+	}
+	for _, kind := range []string{"program", "library", "directory"} {
+		for _, name := range contents[kind] {
+			_, err = tx.Exec(`INSERT INTO contents (package_id, kind, name) VALUES (?, ?, ?)`, id, kind, name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ExportSQLite func takes pkgs []*PackageInformation, file string input and returns error
+// An existing database at file is replaced.
+func ExportSQLite(pkgs []*PackageInformation, file string) error {
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	db, err := sql.Open("sqlite", file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create schema : %v", err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, pkgInfo := range pkgs {
+		if err := exportPackage(tx, pkgInfo); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to export %s : %v", pkgInfo.Name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// runSQLite func takes args []string input and returns error
+func runSQLite(args []string) error {
+	var output, overrides string
+	flags := flag.NewFlagSet("sqlite", flag.ExitOnError)
+	flags.StringVar(&output, "output", "book.db", "Path of the SQLite database to write")
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s sqlite [options] <book|catalog|packages>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("sqlite requires a book, catalog or package directory")
+	}
+	pkgs, err := LoadPackages(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	return ExportSQLite(pkgs, output)
+}
@@ -0,0 +1,65 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestExportSQLite func takes no input and returns t *testing.T
+func TestExportSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlite")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(dir)
+	pkgs := []*PackageInformation{
+		{
+			Name:         "exiv2",
+			Version:      "0.27.2",
+			Commands:     []Command{{Cmd: "cmake ..", Index: 0}, {Cmd: "make install", Index: 1}},
+			Dependencies: Dependencies{Requires: []string{"cmake-3.15.2"}, Optional: []string{"libssh"}},
+			Sources:      []Source{{Archive: "exiv2-0.27.2-Source.tar.gz", BuildTime: "0.2 SBU (Using parallelism=4)"}},
+			Contents:     &Contents{Programs: []string{"exiv2"}, Libraries: []string{"libexiv2.so"}},
+		},
+		{
+			Name:         "gtk",
+			Version:      "3.24.10",
+			Dependencies: Dependencies{Requires: []string{"cmake-3.15.2", "meson-0.51.2"}},
+			Sources:      []Source{{Archive: "gtk+-3.24.10.tar.xz", BuildTime: "1.5 SBU"}},
+		},
+	}
+	file := filepath.Join(dir, "book.db")
+	err = ExportSQLite(pkgs, file)
+	assert.Assert(t, is.Nil(err))
+	// exporting again replaces the database
+	err = ExportSQLite(pkgs, file)
+	assert.Assert(t, is.Nil(err))
+
+	db, err := sql.Open("sqlite", file)
+	assert.Assert(t, is.Nil(err))
+	defer db.Close()
+	var name string
+	err = db.QueryRow(`SELECT p.name FROM packages p
+		JOIN dependencies d ON d.package_id = p.id AND d.name = 'cmake'
+		WHERE p.id NOT IN (SELECT package_id FROM dependencies WHERE name = 'meson')`).Scan(&name)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, name, "exiv2")
+	var sbu float64
+	err = db.QueryRow(`SELECT SUM(sbu) FROM sources`).Scan(&sbu)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, sbu, 1.7)
+	var count int
+	err = db.QueryRow(`SELECT COUNT(*) FROM contents WHERE kind = 'library'`).Scan(&count)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, count, 1)
+	err = db.QueryRow(`SELECT COUNT(*) FROM packages`).Scan(&count)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, count, 2)
+}
@@ -0,0 +1,99 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Build stages commands are classified into
+const (
+	StagePrepare = "prepare"
+	StageBuild   = "build"
+	StageCheck   = "check"
+	StageInstall = "install"
+)
+
+// Stages lists the build stages in the order they run
+var Stages = []string{StagePrepare, StageBuild, StageCheck, StageInstall}
+
+var (
+	// checkPattern matches test suite invocations
+	checkPattern = regexp.MustCompile(`^(make|ninja|meson)\b.*\b(check|test|tests)\b|^ctest\b`)
+	// installPattern matches install steps run from the build tree
+	installPattern = regexp.MustCompile(`^(make|ninja|meson)\b.*\binstall\b|^python[0-9.]*\s+setup\.py\s+install\b|^pip[0-9.]*\s+install\b`)
+	// preparePattern matches source fixups and unpacking done before configuring
+	preparePattern = regexp.MustCompile(`^(sed\b.*\s-[a-zA-Z]*i|patch\s|tar\s+-?[a-zA-Z]*x)`)
+)
+
+// commandLines func takes cmd string input and returns []string
+// Continuation lines are joined and blank lines are dropped.
+func commandLines(cmd string) []string {
+	cmd = strings.ReplaceAll(cmd, "\\\n", " ")
+	lines := make([]string, 0)
+	for _, line := range strings.Split(cmd, "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "&&"))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// matchAny func takes pattern *regexp.Regexp, lines []string input and returns bool
+func matchAny(pattern *regexp.Regexp, lines []string) bool {
+	for _, line := range lines {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAll func takes pattern *regexp.Regexp, lines []string input and returns bool
+func matchAll(pattern *regexp.Regexp, lines []string) bool {
+	for _, line := range lines {
+		if !pattern.MatchString(line) {
+			return false
+		}
+	}
+	return len(lines) > 0
+}
+
+// commandStages func takes cmds []Command input and returns []string
+// The build stage of each command, in the order of cmds.
+func commandStages(cmds []Command) []string {
+	stages := make([]string, 0, len(cmds))
+	building := false
+	for _, cmd := range cmds {
+		lines := commandLines(cmd.Cmd)
+		stage := StageBuild
+		switch {
+		case cmd.Root || matchAny(installPattern, lines):
+			stage = StageInstall
+		case matchAll(checkPattern, lines):
+			stage = StageCheck
+		case !building && (cmd.Fixup || matchAll(preparePattern, lines)):
+			stage = StagePrepare
+		}
+		if stage == StageBuild {
+			building = true
+		}
+		stages = append(stages, stage)
+	}
+	return stages
+}
+
+// ClassifyCommands func takes cmds []Command input and returns map[string][]Command
+// Root commands and install steps go to the install stage, test suite runs to
+// check and source fixups ahead of the first build command, commands marked
+// Fixup among them, to prepare. Everything else is part of the build.
+func ClassifyCommands(cmds []Command) map[string][]Command {
+	stages := make(map[string][]Command, len(Stages))
+	for i, stage := range commandStages(cmds) {
+		stages[stage] = append(stages[stage], cmds[i])
+	}
+	return stages
+}
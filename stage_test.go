@@ -0,0 +1,42 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// TestClassifyCommands func takes no input and returns t *testing.T
+func TestClassifyCommands(t *testing.T) {
+	stages := ClassifyCommands([]Command{
+		{Cmd: "tar -xf ../tcl8.6.9-html.tar.gz --strip-components=1", Index: 0},
+		{Cmd: "export SRCDIR=`pwd` &&\n\ncd unix &&\n\n./configure --prefix=/usr \\\n            --mandir=/usr/share/man &&\nmake", Index: 1},
+		{Cmd: "make test", Index: 2},
+		{Cmd: "make install &&\nmake install-private-headers &&\nln -v -sf tclsh8.6 /usr/bin/tclsh", Index: 3, Root: true},
+	})
+	assert.Equal(t, len(stages[StagePrepare]), 1)
+	assert.Equal(t, len(stages[StageBuild]), 1)
+	assert.Equal(t, stages[StageCheck][0].Index, 2)
+	assert.Equal(t, stages[StageInstall][0].Index, 3)
+
+	lfs := ClassifyCommands([]Command{
+		{Cmd: "sed -i '/def add_multiarch_paths/a \\        return' setup.py", Index: 0},
+		{Cmd: "./configure --prefix=/tools --without-ensurepip", Index: 1},
+		{Cmd: "sed -i 's/foo/bar/' Makefile", Index: 2},
+		{Cmd: "make", Index: 3},
+		{Cmd: "make install", Index: 4},
+	})
+	assert.Equal(t, len(lfs[StagePrepare]), 1)
+	assert.Equal(t, len(lfs[StageBuild]), 3)
+	assert.Equal(t, lfs[StageInstall][0].Index, 4)
+
+	fixups := ClassifyCommands([]Command{
+		{Cmd: "cd src &&\nsed -e 's/-Werror//' -i Makefile", Index: 0, Fixup: true},
+		{Cmd: "cd src &&\nsed -e 's/-Werror//' -i Makefile", Index: 1},
+	})
+	assert.Equal(t, fixups[StagePrepare][0].Index, 0)
+	assert.Equal(t, fixups[StageBuild][0].Index, 1)
+}
@@ -0,0 +1,130 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the helper functions available to output templates
+var templateFuncs = template.FuncMap{
+	"shellquote":   shellQuote,
+	"join":         strings.Join,
+	"lower":        strings.ToLower,
+	"upper":        strings.ToUpper,
+	"trim":         strings.TrimSpace,
+	"replace":      strings.ReplaceAll,
+	"indent":       indent,
+	"basename":     path.Base,
+	"depname":      DependencyName,
+	"depnames":     dependencyNames,
+	"version":      MustParseVersion,
+	"majorminor":   func(v string) string { return MustParseVersion(v).MajorMinor() },
+	"rootcommands": func(cmds []Command) []Command { return filterCommands(cmds, true) },
+	"usercommands": func(cmds []Command) []Command { return filterCommands(cmds, false) },
+}
+
+// shellQuote func takes s string input and returns string
+// The result is a single quoted POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// indent func takes n int, s string input and returns string
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// filterCommands func takes cmds []Command, root bool input and returns []Command
+func filterCommands(cmds []Command, root bool) []Command {
+	filtered := make([]Command, 0, len(cmds))
+	for _, cmd := range cmds {
+		if cmd.Root == root {
+			filtered = append(filtered, cmd)
+		}
+	}
+	return filtered
+}
+
+// ReadTemplate func takes file string input and returns *template.Template, error
+func ReadTemplate(file string) (*template.Template, error) {
+	tmpl, err := template.New(filepath.Base(file)).Funcs(templateFuncs).ParseFiles(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s : %v", file, err)
+	}
+	return tmpl, nil
+}
+
+// ParseTemplate func takes name, text string input and returns *template.Template, error
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s : %v", name, err)
+	}
+	return tmpl, nil
+}
+
+// Render func takes tmpl *template.Template, data interface{} input and returns []byte, error
+func Render(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s : %v", tmpl.Name(), err)
+	}
+	return buf.Bytes(), nil
+}
+
+// templateExtension func takes file string input and returns string
+// The extension left after removing .tmpl, e.g. ".spec" for spec.spec.tmpl.
+func templateExtension(file string) string {
+	ext := filepath.Ext(strings.TrimSuffix(filepath.Base(file), ".tmpl"))
+	if ext == "" {
+		return ".txt"
+	}
+	return ext
+}
+
+// DefaultFilename func takes pkgInfo *PackageInformation, ext string input and returns string
+// The version is left out when the page has none.
+func DefaultFilename(pkgInfo *PackageInformation, ext string) string {
+	if pkgInfo.Version == "" {
+		return pkgInfo.Name + ext
+	}
+	return pkgInfo.Name + "-" + pkgInfo.Version + ext
+}
+
+// OutputFilename func takes nameTmpl *template.Template, data interface{}, filename string input and returns string, error
+// Without a filename template the given filename is used. data is a package,
+// or the list of packages for -template-all. The name must stay below the
+// destination directory, absolute names and names leaving it with .. are
+// rejected.
+func OutputFilename(nameTmpl *template.Template, data interface{}, filename string) (string, error) {
+	if nameTmpl != nil {
+		name, err := Render(nameTmpl, data)
+		if err != nil {
+			return "", err
+		}
+		rendered := strings.TrimSpace(string(name))
+		if rendered == "" {
+			return "", fmt.Errorf("filename template rendered an empty name for %s", filename)
+		}
+		filename = rendered
+	}
+	cleaned := path.Clean(filepath.ToSlash(filename))
+	if path.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("file name %q is not below the destination directory", filename)
+	}
+	return cleaned, nil
+}
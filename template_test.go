@@ -0,0 +1,77 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestRender func takes no input and returns t *testing.T
+func TestRender(t *testing.T) {
+	pkgInfo := &PackageInformation{
+		Name:    "tcl",
+		Version: "8.6.9",
+		Commands: []Command{
+			{Cmd: "./configure --prefix=/usr", Index: 0},
+			{Cmd: "make install", Index: 1, Root: true},
+		},
+		Dependencies: Dependencies{Requires: []string{"zlib-1.2.11"}},
+		Sources:      []Source{{Archive: "https://downloads.sourceforge.net/tcl/tcl8.6.9-src.tar.gz"}},
+		Description:  "Tool Command Language, it's a scripting language",
+	}
+	text := `{{ .Name | upper }} {{ majorminor .Version }} {{ (version .Version).Parts }}
+desc={{ shellquote .Description }}
+deps={{ join (depnames .Dependencies.Requires) "," }}
+src={{ range .Sources }}{{ basename .Archive }}{{ end }}
+{{ range usercommands .Commands }}user: {{ .Cmd }}
+{{ end }}{{ range rootcommands .Commands }}root: {{ .Cmd }}
+{{ end }}`
+	tmpl, err := ParseTemplate("test", text)
+	assert.Assert(t, is.Nil(err))
+	out, err := Render(tmpl, pkgInfo)
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, string(out), `TCL 8.6 [8 6 9]
+desc='Tool Command Language, it'\''s a scripting language'
+deps=zlib
+src=tcl8.6.9-src.tar.gz
+user: ./configure --prefix=/usr
+root: make install
+`)
+
+	all, err := ParseTemplate("all", `{{ range . }}{{ .Name }}-{{ .Version }} {{ end }}`)
+	assert.Assert(t, is.Nil(err))
+	out, err = Render(all, []*PackageInformation{pkgInfo, {Name: "tk", Version: "8.6.9"}})
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, string(out), "tcl-8.6.9 tk-8.6.9 ")
+
+	filename, err := OutputFilename(nil, pkgInfo, DefaultFilename(pkgInfo, ".yaml"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, filename, "tcl-8.6.9.yaml")
+	nameTmpl, err := ParseTemplate("filename", `{{ .Name }}/{{ majorminor .Version }}/{{ .Name }}.yaml`)
+	assert.Assert(t, is.Nil(err))
+	filename, err = OutputFilename(nameTmpl, pkgInfo, DefaultFilename(pkgInfo, ".yaml"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, filename, "tcl/8.6/tcl.yaml")
+	for _, text := range []string{"../{{ .Name }}.yaml", "/tmp/{{ .Name }}.yaml", "{{ .Name }}/../../x"} {
+		nameTmpl, err = ParseTemplate("filename", text)
+		assert.Assert(t, is.Nil(err))
+		_, err = OutputFilename(nameTmpl, pkgInfo, DefaultFilename(pkgInfo, ".yaml"))
+		assert.ErrorContains(t, err, "not below the destination directory", text)
+	}
+	nameTmpl, err = ParseTemplate("filename", "./{{ .Name }}//docs/../{{ .Name }}.md")
+	assert.Assert(t, is.Nil(err))
+	filename, err = OutputFilename(nameTmpl, pkgInfo, DefaultFilename(pkgInfo, ".yaml"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, filename, "tcl/tcl.md")
+	listTmpl, err := ParseTemplate("filename", `index-{{ len . }}.md`)
+	assert.Assert(t, is.Nil(err))
+	filename, err = OutputFilename(listTmpl, []*PackageInformation{pkgInfo}, "index.md")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, filename, "index-1.md")
+	assert.Equal(t, templateExtension("rpm.spec.tmpl"), ".spec")
+	assert.Equal(t, templateExtension("report.tmpl"), ".txt")
+}
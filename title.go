@@ -0,0 +1,127 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Confidence levels reported for the detected application name and version
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// sectionPrefix matches leading section numbers such as "5.30. ", "II. " or "Chapter 10. "
+var sectionPrefix = regexp.MustCompile(`^(?i:chapter\s+|appendix\s+|part\s+)?([0-9]+|[IVXL]+|[A-Z])(\.[0-9]+)*\.\s+`)
+
+// cleanTitle func takes title string input and returns string
+// Non-breaking spaces are normalised and section numbering is removed.
+func cleanTitle(title string) string {
+	title = strings.ReplaceAll(title, "&nbsp;", " ")
+	title = strings.ReplaceAll(title, "\u00a0", " ")
+	title = strings.Join(strings.Fields(title), " ")
+	return sectionPrefix.ReplaceAllString(title, "")
+}
+
+// splitNameVersion func takes title string input and returns name, version string
+// The version is the text after the last "-" that parses as a version, so
+// hyphenated names such as xorg-server-1.20.5 keep their hyphens. version is
+// empty when the title carries no version.
+func splitNameVersion(title string) (string, string) {
+	for i := strings.LastIndex(title, "-"); i > 0; i = strings.LastIndex(title[:i], "-") {
+		version := title[i+1:]
+		if strings.Contains(version, " ") {
+			break
+		}
+		if _, err := ParseVersion(version); err == nil {
+			return title[:i], version
+		}
+	}
+	return title, ""
+}
+
+// normalizeName func takes name string input and returns string
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// compactName func takes name string input and returns string
+// compactName keeps only letters and digits so names can be compared loosely.
+func compactName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// namesAgree func takes name, other string input and returns bool
+// Prefix and suffix matches are accepted so freetype agrees with freetype2
+// and the LFS anchor ch-tools-Python agrees with python.
+func namesAgree(name, other string) bool {
+	a, b := compactName(name), compactName(other)
+	if a == "" || b == "" {
+		return false
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return strings.HasPrefix(b, a) || strings.HasSuffix(b, a)
+}
+
+// pageName func takes page string input and returns string
+func pageName(page string) string {
+	if page == "" {
+		return ""
+	}
+	base := filepath.Base(page)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// corroborate func takes application *Application, anchor, page string input and returns
+// The detected name is checked against the h1.sect1 anchor id and the page
+// filename, setting Confidence and recording Diagnostics on disagreement.
+// Confidence is high when every signal agrees, medium when some disagree or
+// there are none and low when none agree or the version is missing.
+func corroborate(application *Application, anchor, page string) {
+	if application.VersionGuessed {
+		application.Confidence = ConfidenceLow
+		application.Diagnostics = append(application.Diagnostics,
+			"no version found in title")
+	}
+	signals := map[string]string{"anchor id": anchor, "page filename": pageName(page)}
+	agreed, checked := 0, 0
+	for _, label := range []string{"anchor id", "page filename"} {
+		signal := signals[label]
+		if signal == "" {
+			continue
+		}
+		checked++
+		if namesAgree(application.Name, signal) {
+			agreed++
+			continue
+		}
+		application.Diagnostics = append(application.Diagnostics,
+			fmt.Sprintf("name %q does not match %s %q", application.Name, label, signal))
+	}
+	if application.Confidence != "" {
+		return
+	}
+	switch {
+	case checked > 0 && agreed == checked:
+		application.Confidence = ConfidenceHigh
+	case checked == 0, agreed > 0:
+		application.Confidence = ConfidenceMedium
+	default:
+		application.Confidence = ConfidenceLow
+	}
+}
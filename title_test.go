@@ -0,0 +1,82 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestSplitNameVersion func takes no input and returns t *testing.T
+func TestSplitNameVersion(t *testing.T) {
+	tests := []struct {
+		title   string
+		name    string
+		version string
+	}{
+		{"Exiv2-0.27.2", "Exiv2", "0.27.2"},
+		{"xorg-server-1.20.5", "xorg-server", "1.20.5"},
+		{"gst-plugins-base-1.16.0", "gst-plugins-base", "1.16.0"},
+		{"MIT Kerberos V5-1.17", "MIT Kerberos V5", "1.17"},
+		{"Linux-PAM-1.3.1", "Linux-PAM", "1.3.1"},
+		{"Graphics and Font Libraries", "Graphics and Font Libraries", ""},
+		{"libjpeg-turbo", "libjpeg-turbo", ""},
+	}
+	for _, tt := range tests {
+		name, version := splitNameVersion(cleanTitle(tt.title))
+		assert.Equal(t, name, tt.name, tt.title)
+		assert.Equal(t, version, tt.version, tt.title)
+	}
+	assert.Equal(t, cleanTitle("Chapter 10. Graphics and Font Libraries"), "Graphics and Font Libraries")
+	assert.Equal(t, cleanTitle("5.30.&nbsp;Python-3.7.4"), "Python-3.7.4")
+	assert.Equal(t, cleanTitle("X.Org-7"), "X.Org-7")
+}
+
+// TestExtractApplicationFromPage func takes no input and returns t *testing.T
+func TestExtractApplicationFromPage(t *testing.T) {
+	htmlPkg := `<html>
+  <head>
+    <title>
+      Xorg-Server-1.20.5
+    </title>
+  </head>
+  <body>
+    <div class="sect1">
+      <h1 class="sect1">
+        <a id="xorg-server" name="xorg-server"></a>Xorg-Server-1.20.5
+      </h1>
+      <div class="package">
+        <p>The <span class="application">Xorg Server</span> is the core of the X Window system.</p>
+      </div>
+    </div>
+  </body>
+</html>
+`
+	doc, err := ReadDoc([]byte(htmlPkg))
+	assert.Assert(t, is.Nil(err))
+	app, err := ExtractApplicationFromPage(doc, "x/xorg-server.html")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, app.Name, "xorg-server")
+	assert.Equal(t, app.Version, "1.20.5")
+	assert.Equal(t, app.Confidence, ConfidenceHigh)
+	assert.Equal(t, len(app.Diagnostics), 0)
+
+	app, err = ExtractApplicationFromPage(doc, "x/xwayland.html")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, app.Confidence, ConfidenceMedium)
+	assert.DeepEqual(t, app.Diagnostics, []string{`name "xorg-server" does not match page filename "xwayland"`})
+
+	spaced := `<html><head><title>Chapter&nbsp;10.&nbsp;Graphics and Font Libraries</title></head>
+<body><h1 class="sect1"><a id="graphlib" name="graphlib"></a>Graphics and Font Libraries</h1></body></html>`
+	doc, err = ReadDoc([]byte(spaced))
+	assert.Assert(t, is.Nil(err))
+	app, err = ExtractApplicationFromPage(doc, "general/graphlib.html")
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, app.Name, "graphics-and-font-libraries")
+	assert.Equal(t, app.Version, "")
+	assert.Assert(t, app.VersionGuessed)
+	assert.Equal(t, app.Confidence, ConfidenceLow)
+}
@@ -0,0 +1,182 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UpgradeCandidate struct for upgradecandidate
+type UpgradeCandidate struct {
+	Name                string             `json:"name" yaml:"name"`
+	InstalledVersion    string             `json:"installed_version" yaml:"installed_version"`
+	NewVersion          string             `json:"new_version" yaml:"new_version"`
+	Sources             []Source           `json:"sources" yaml:"sources"`
+	ChangedDependencies []DependencyChange `json:"changed_dependencies" yaml:"changed_dependencies"`
+}
+
+// DependencyChange struct for dependencychange
+// An empty InstalledVersion means the dependency is not installed.
+type DependencyChange struct {
+	Name             string `json:"name" yaml:"name"`
+	InstalledVersion string `json:"installed_version" yaml:"installed_version"`
+	NewVersion       string `json:"new_version" yaml:"new_version"`
+}
+
+// ReadManifest func takes file string input and returns map[string]string, error
+// The manifest maps installed package names to versions. YAML and JSON
+// manifests hold a list of name/version entries, any other file is read as
+// "name version" lines with # comments.
+func ReadManifest(file string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	manifest := make(map[string]string)
+	entries := make([]PackageSummary, 0)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		err = json.Unmarshal(b, &entries)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &entries)
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("invalid manifest line %q", line)
+			}
+			entries = append(entries, PackageSummary{Name: fields[0], Version: fields[1]})
+		}
+		err = scanner.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s : %v", file, err)
+	}
+	for _, entry := range entries {
+		manifest[strings.ToLower(entry.Name)] = entry.Version
+	}
+	return manifest, nil
+}
+
+// outdated func takes installed, available string input and returns bool
+func outdated(installed, available string) bool {
+	return CompareVersions(installed, available) < 0
+}
+
+// UpgradeCandidates func takes manifest map[string]string, pkgs []*PackageInformation input and returns []UpgradeCandidate
+// Candidates are ordered by the dependency graph so dependencies come first.
+func UpgradeCandidates(manifest map[string]string, pkgs []*PackageInformation) []UpgradeCandidate {
+	graph := NewDependencyGraph(pkgs, true)
+	candidates := make([]UpgradeCandidate, 0)
+	for _, name := range graph.Order(nil) {
+		pkg := graph.Packages[name]
+		installed, ok := manifest[name]
+		if !ok {
+			if installed, ok = manifest[pkg.Name]; !ok {
+				continue
+			}
+		}
+		if !outdated(installed, pkg.Version) {
+			continue
+		}
+		candidate := UpgradeCandidate{
+			Name:                name,
+			InstalledVersion:    installed,
+			NewVersion:          pkg.Version,
+			Sources:             pkg.Sources,
+			ChangedDependencies: []DependencyChange{},
+		}
+		for _, dep := range graph.Edges(name) {
+			depInstalled, ok := manifest[dep]
+			depVersion := graph.Packages[dep].Version
+			if ok && !outdated(depInstalled, depVersion) {
+				continue
+			}
+			candidate.ChangedDependencies = append(candidate.ChangedDependencies, DependencyChange{
+				Name:             dep,
+				InstalledVersion: depInstalled,
+				NewVersion:       depVersion,
+			})
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// upgradeText func takes candidates []UpgradeCandidate input and returns []byte
+func upgradeText(candidates []UpgradeCandidate) []byte {
+	var buf bytes.Buffer
+	for _, c := range candidates {
+		fmt.Fprintf(&buf, "%s %s -> %s\n", c.Name, c.InstalledVersion, c.NewVersion)
+		for _, src := range c.Sources {
+			fmt.Fprintf(&buf, "    source: %s %s\n", src.Archive, src.MD5Sum)
+		}
+		for _, dep := range c.ChangedDependencies {
+			installed := dep.InstalledVersion
+			if installed == "" {
+				installed = "not installed"
+			}
+			fmt.Fprintf(&buf, "    dependency: %s %s -> %s\n", dep.Name, installed, dep.NewVersion)
+		}
+	}
+	return buf.Bytes()
+}
+
+// runUpgrade func takes args []string input and returns error
+func runUpgrade(args []string) error {
+	var (
+		manifestFile string
+		overrides    string
+		asjson       bool
+	)
+	flags := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	flags.StringVar(&manifestFile, "manifest", "", "Path to the installed package manifest")
+	flags.StringVar(&overrides, "overrides", "", "Path to a directory of package override files")
+	flags.BoolVar(&asjson, "json", false, "Output JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s upgrade -manifest <file> [options] <book>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if manifestFile == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("upgrade requires a manifest and a book or package directory")
+	}
+	manifest, err := ReadManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+	pkgs, err := LoadPackages(flags.Arg(0), overrides)
+	if err != nil {
+		return err
+	}
+	candidates := UpgradeCandidates(manifest, pkgs)
+	if asjson {
+		jsn, err := json.MarshalIndent(candidates, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to convert to json : %v", err)
+		}
+		fmt.Printf("%s\n", jsn)
+		return nil
+	}
+	fmt.Printf("%s", upgradeText(candidates))
+	return nil
+}
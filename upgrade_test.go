@@ -0,0 +1,65 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestUpgradeCandidates func takes no input and returns t *testing.T
+func TestUpgradeCandidates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	assert.Assert(t, is.Nil(err))
+	defer os.RemoveAll(dir)
+	text := `# installed packages
+cmake 3.15.2
+exiv2 0.27.1
+curl 7.65.3
+`
+	err = ioutil.WriteFile(path.Join(dir, "installed.txt"), []byte(text), 0644)
+	assert.Assert(t, is.Nil(err))
+	manifest, err := ReadManifest(path.Join(dir, "installed.txt"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, manifest["exiv2"], "0.27.1")
+
+	yml := `- name: cmake
+  version: 3.15.2
+- name: exiv2
+  version: 0.27.1
+`
+	err = ioutil.WriteFile(path.Join(dir, "installed.yaml"), []byte(yml), 0644)
+	assert.Assert(t, is.Nil(err))
+	ymlManifest, err := ReadManifest(path.Join(dir, "installed.yaml"))
+	assert.Assert(t, is.Nil(err))
+	assert.Equal(t, len(ymlManifest), 2)
+
+	pkgs := []*PackageInformation{
+		{
+			Name:         "exiv2",
+			Version:      "0.27.2",
+			Dependencies: Dependencies{Requires: []string{"cmake-3.16.0"}, Recommended: []string{"curl-7.65.3", "libssh-0.9.0"}},
+			Sources:      []Source{{Archive: "http://www.exiv2.org/builds/exiv2-0.27.2-Source.tar.gz", MD5Sum: "8c39c39dc8141bb158e8e9d663bcbf21"}},
+		},
+		{Name: "cmake", Version: "3.16.0"},
+		{Name: "curl", Version: "7.65.3"},
+		{Name: "libssh", Version: "0.9.0"},
+	}
+	candidates := UpgradeCandidates(manifest, pkgs)
+	assert.Equal(t, len(candidates), 2)
+	assert.Equal(t, candidates[0].Name, "cmake")
+	assert.Equal(t, candidates[1].Name, "exiv2")
+	assert.Equal(t, candidates[1].NewVersion, "0.27.2")
+	assert.DeepEqual(t, candidates[1].ChangedDependencies, []DependencyChange{
+		{Name: "cmake", InstalledVersion: "3.15.2", NewVersion: "3.16.0"},
+		{Name: "libssh", InstalledVersion: "", NewVersion: "0.9.0"},
+	})
+	fmt.Printf("%s\n", upgradeText(candidates))
+}
@@ -0,0 +1,225 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Version struct for version
+//
+// Parts holds the numeric components of the version, Prefix a leading
+// revision marker such as "r" or "v" and Suffix anything following the
+// numeric components, e.g. "d" for 1.1.1d or "rc1" for 2.0rc1. Guessed is set
+// when the version was not found on the page.
+type Version struct {
+	Raw     string `json:"raw" yaml:"raw"`
+	Prefix  string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Parts   []int  `json:"parts" yaml:"parts"`
+	Suffix  string `json:"suffix,omitempty" yaml:"suffix,omitempty"`
+	Guessed bool   `json:"guessed,omitempty" yaml:"guessed,omitempty"`
+}
+
+// versionNoise holds archive suffixes that are not part of the version
+var versionNoise = []string{"source", "src", "sources"}
+
+// preReleases holds suffix prefixes that sort before the release itself
+var preReleases = []string{"alpha", "beta", "pre", "rc"}
+
+// shortPreReleases are pre-release prefixes only when a number follows, as in
+// 1.0a1; a bare letter is a later release such as 1.1.1a
+var shortPreReleases = []string{"a", "b"}
+
+// ParseVersion func takes s string input and returns Version, error
+func ParseVersion(s string) (Version, error) {
+	version := Version{Raw: s}
+	v := strings.TrimSpace(s)
+	if v == "" {
+		return version, fmt.Errorf("version empty")
+	}
+	if len(v) > 1 && (v[0] == 'r' || v[0] == 'v' || v[0] == 'R' || v[0] == 'V') && unicode.IsDigit(rune(v[1])) {
+		version.Prefix = strings.ToLower(v[:1])
+		v = v[1:]
+	}
+	if !unicode.IsDigit(rune(v[0])) {
+		return version, fmt.Errorf("version %q does not start with a number", s)
+	}
+	for v != "" {
+		i := strings.IndexFunc(v, func(r rune) bool { return !unicode.IsDigit(r) })
+		if i < 0 {
+			i = len(v)
+		}
+		part, err := strconv.Atoi(v[:i])
+		if err != nil {
+			return version, fmt.Errorf("version %q : %v", s, err)
+		}
+		version.Parts = append(version.Parts, part)
+		v = v[i:]
+		if len(v) > 1 && v[0] == '.' && unicode.IsDigit(rune(v[1])) {
+			v = v[1:]
+			continue
+		}
+		break
+	}
+	suffix := strings.TrimLeft(v, "-._")
+	for _, noise := range versionNoise {
+		if strings.EqualFold(suffix, noise) {
+			suffix = ""
+		}
+	}
+	version.Suffix = suffix
+	return version, nil
+}
+
+// MustParseVersion func takes s string input and returns Version
+// Unparsable versions are returned with only Raw and Guessed set.
+func MustParseVersion(s string) Version {
+	version, err := ParseVersion(s)
+	if err != nil {
+		return Version{Raw: s, Guessed: true}
+	}
+	return version
+}
+
+// String func takes no input and returns string
+func (v Version) String() string {
+	return v.Raw
+}
+
+// MajorMinor func takes no input and returns string
+func (v Version) MajorMinor() string {
+	switch len(v.Parts) {
+	case 0:
+		return ""
+	case 1:
+		return strconv.Itoa(v.Parts[0])
+	}
+	return strconv.Itoa(v.Parts[0]) + "." + strconv.Itoa(v.Parts[1])
+}
+
+// preRelease func takes no input and returns bool
+func (v Version) preRelease() bool {
+	suffix := strings.ToLower(v.Suffix)
+	for _, pre := range preReleases {
+		if strings.HasPrefix(suffix, pre) {
+			return true
+		}
+	}
+	for _, pre := range shortPreReleases {
+		if len(suffix) > len(pre) && strings.HasPrefix(suffix, pre) && unicode.IsDigit(rune(suffix[len(pre)])) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare func takes other Version input and returns int
+// The result is -1, 0 or 1 when v is older, equal or newer than other.
+// Versions that could not be parsed are compared as raw strings.
+func (v Version) Compare(other Version) int {
+	if len(v.Parts) == 0 || len(other.Parts) == 0 {
+		return strings.Compare(v.Raw, other.Raw)
+	}
+	n := len(v.Parts)
+	if len(other.Parts) > n {
+		n = len(other.Parts)
+	}
+	for i := 0; i < n; i++ {
+		var a, b int
+		if i < len(v.Parts) {
+			a = v.Parts[i]
+		}
+		if i < len(other.Parts) {
+			b = other.Parts[i]
+		}
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	switch {
+	case v.Suffix == other.Suffix:
+		return 0
+	case v.Suffix == "":
+		if other.preRelease() {
+			return 1
+		}
+		return -1
+	case other.Suffix == "":
+		if v.preRelease() {
+			return -1
+		}
+		return 1
+	}
+	vPre, otherPre := v.preRelease(), other.preRelease()
+	switch {
+	case vPre && !otherPre:
+		return -1
+	case otherPre && !vPre:
+		return 1
+	}
+	vWord, vNum, vRest := suffixParts(v.Suffix)
+	otherWord, otherNum, otherRest := suffixParts(other.Suffix)
+	if vPre {
+		vWord, otherWord = preReleaseTag(vWord), preReleaseTag(otherWord)
+	}
+	if c := strings.Compare(vWord, otherWord); c != 0 {
+		return c
+	}
+	switch {
+	case vNum < otherNum:
+		return -1
+	case vNum > otherNum:
+		return 1
+	}
+	return strings.Compare(vRest, otherRest)
+}
+
+// suffixParts func takes suffix string input and returns string, int, string
+// The lowercased word leading suffix, the number following it, -1 without
+// one, and whatever is left, e.g. "rc", 10, "" for rc10.
+func suffixParts(suffix string) (string, int, string) {
+	suffix = strings.ToLower(suffix)
+	i := strings.IndexFunc(suffix, unicode.IsDigit)
+	if i < 0 {
+		return suffix, -1, ""
+	}
+	word, rest := strings.TrimRight(suffix[:i], "-._"), suffix[i:]
+	j := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsDigit(r) })
+	if j < 0 {
+		j = len(rest)
+	}
+	num, err := strconv.Atoi(rest[:j])
+	if err != nil {
+		return word, -1, rest
+	}
+	return word, num, rest[j:]
+}
+
+// preReleaseTag func takes word string input and returns string
+// The pre-release word as a key that sorts alpha, beta, pre and rc in that
+// order, with a and b standing for alpha and beta.
+func preReleaseTag(word string) string {
+	for i, pre := range preReleases {
+		if strings.HasPrefix(word, pre) {
+			return strconv.Itoa(i)
+		}
+	}
+	for i, pre := range shortPreReleases {
+		if word == pre {
+			return strconv.Itoa(i)
+		}
+	}
+	return word
+}
+
+// CompareVersions func takes a, b string input and returns int
+func CompareVersions(a, b string) int {
+	return MustParseVersion(a).Compare(MustParseVersion(b))
+}
@@ -0,0 +1,76 @@
+// Copyright © 2019 Brett Smith <xbcsmith@gmail.com>, . All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+// TestParseVersion func takes no input and returns t *testing.T
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		raw    string
+		prefix string
+		parts  []int
+		suffix string
+	}{
+		{"8.6.9", "", []int{8, 6, 9}, ""},
+		{"2.10.1", "", []int{2, 10, 1}, ""},
+		{"0.27.2-Source", "", []int{0, 27, 2}, ""},
+		{"r1234", "r", []int{1234}, ""},
+		{"20200101", "", []int{20200101}, ""},
+		{"1.1.1d", "", []int{1, 1, 1}, "d"},
+		{"2.0rc1", "", []int{2, 0}, "rc1"},
+		{"v3.15.2", "v", []int{3, 15, 2}, ""},
+	}
+	for _, tt := range tests {
+		version, err := ParseVersion(tt.raw)
+		assert.Assert(t, is.Nil(err), tt.raw)
+		assert.Equal(t, version.Prefix, tt.prefix, tt.raw)
+		assert.DeepEqual(t, version.Parts, tt.parts)
+		assert.Equal(t, version.Suffix, tt.suffix, tt.raw)
+		assert.Equal(t, version.String(), tt.raw)
+	}
+	_, err := ParseVersion("environment")
+	assert.ErrorContains(t, err, "does not start with a number")
+	assert.Assert(t, MustParseVersion("environment").Guessed)
+	assert.Equal(t, MustParseVersion("8.6.9").MajorMinor(), "8.6")
+}
+
+// TestCompareVersions func takes no input and returns t *testing.T
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"8.6.9", "8.6.10", -1},
+		{"2.10.1", "2.9.1", 1},
+		{"0.27.2", "0.27.2-Source", 0},
+		{"1.0", "1.0.0", 0},
+		{"1.1.1d", "1.1.1c", 1},
+		{"1.1.1d", "1.1.1", 1},
+		{"2.0rc1", "2.0", -1},
+		{"1.1.1a", "1.1.1", 1},
+		{"1.1.1a", "1.1.1b", -1},
+		{"1.0a1", "1.0", -1},
+		{"1.0b2", "1.0", -1},
+		{"2.0rc9", "2.0rc10", -1},
+		{"2.0rc10", "2.0rc9", 1},
+		{"1.1.1rc1", "1.1.1d", -1},
+		{"1.1.1d", "1.1.1rc1", 1},
+		{"1.0beta2", "1.0rc1", -1},
+		{"1.0a2", "1.0beta1", -1},
+		{"1.0pre1", "1.0alpha3", 1},
+		{"1.0-rc2", "1.0rc2", 0},
+		{"1.1.1z", "1.1.1za", -1},
+		{"r1234", "r1235", -1},
+		{"20200101", "20191231", 1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, CompareVersions(tt.a, tt.b), tt.want, tt.a+" vs "+tt.b)
+	}
+}